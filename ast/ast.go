@@ -3,14 +3,17 @@ package ast
 import (
 	"errors"
 	"fmt"
+	"math"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 	"unicode"
 	"unicode/utf8"
 
 	"github.com/rosshpayne/graph-sdl/internal/token"
+	"github.com/rosshpayne/graph-sdl/logger"
 )
 
 //
@@ -44,6 +47,31 @@ type InputValueProvider interface {
 type InputValue_ struct {
 	InputValueProvider
 	Loc *Loc_
+	Span_
+}
+
+// Clone returns a deep copy of the input value.
+func (iv *InputValue_) Clone() *InputValue_ {
+	if iv == nil {
+		return nil
+	}
+	return &InputValue_{InputValueProvider: cloneValue(iv.InputValueProvider), Loc: iv.Loc.Clone(), Span_: iv.Span_}
+}
+
+// cloneValue copies the composite input values (lists, objects, enum values). All other input values
+// are immutable and are shared.
+func cloneValue(v InputValueProvider) InputValueProvider {
+	switch x := v.(type) {
+	case List_:
+		return x.Clone()
+	case ObjectVals:
+		return x.Clone()
+	case *EnumValue_:
+		return x.Clone()
+	case *Scalar_:
+		return x.Clone()
+	}
+	return v
 }
 
 //func (iv *InputValue_) InputValueNode() {}
@@ -52,13 +80,15 @@ func (iv *InputValue_) String() string {
 
 	switch x := iv.InputValueProvider.(type) {
 	case RawString_:
-		return token.RAWSTRINGDEL + iv.InputValueProvider.String() + token.RAWSTRINGDEL //+ "-" + iv.dTString()
+		if printableAsBlockString(string(x)) {
+			return blockString(string(x))
+		}
+		return quoteString(string(x))
 	case String_:
-		return token.STRINGDEL + iv.InputValueProvider.String() + token.STRINGDEL //+ "-" + iv.dTString() + iv.Loc.String()
+		return quoteString(string(x))
 	case *Scalar_:
-		switch x.Name {
-		case "Time":
-			return fmt.Sprintf("%q", x.TimeV.String())
+		if len(x.Data) > 0 {
+			return x.Data
 		}
 	}
 	if iv.InputValueProvider == nil { // interface is not populated with concrete value
@@ -97,6 +127,8 @@ func (iv *InputValue_) isType() TypeFlag_ {
 		return NULL
 	case List_:
 		return LIST
+	case Variable_:
+		return VARIABLE
 		// case *Input_:		// commented out on 19/3/2020 Input_ is not an InputValue
 		// 	return INPUT
 	}
@@ -124,7 +156,6 @@ func (iv *InputValue_) IsScalar() bool {
 // err contains all errors caught during validation
 func (a *InputValue_) CheckInputValueType(refType *GQLtype, nm Name_, err *[]error) {
 
-	fmt.Println("=========== CheckInputValueType ==============")
 	// is reqType a valid type if not abort
 	if refType.isType() == ILLEGAL {
 		return
@@ -133,64 +164,52 @@ func (a *InputValue_) CheckInputValueType(refType *GQLtype, nm Name_, err *[]err
 		return
 	}
 	// what type is the default value
-	var atPosition string
-	if nm.Loc == nil {
-		atPosition = ""
-	} else {
-		atPosition = a.AtPosition()
+	var atPosition *Loc_
+	if nm.Loc != nil {
+		atPosition = a.Loc
 	}
 	switch valueType := a.InputValueProvider.(type) {
 
+	case Variable_:
+		// the type of a variable is checked against its definition in the operation, see parser.ValidateExecutable
+		return
+
 	case List_:
 		// [ "ads", "wer" ]
 		// single instance data
-		fmt.Println("=========== CheckInputValueType  List_ ==============")
-		fmt.Printf("name: %s\n", refType.Name_)
-		fmt.Printf("constrint: %08b\n", refType.Constraint)
-		fmt.Printf("depth: %d\n", refType.Depth)
-		fmt.Println("defType ", a.isType(), a.IsScalar())
-		fmt.Println("refType ", refType.isType())
+		debug("check list value", logger.Type(refType.Name_.String()), logger.Any("argument", nm.String()), logger.Any("depth", refType.Depth))
 
 		if refType.Depth == 0 { // required type is not a LIST
-			*err = append(*err, fmt.Errorf(`Expected a %s for argument %q, got a List, %s`, refType.isType(), nm, atPosition))
+			*err = append(*err, Diagf(CodeValue, atPosition, `Expected a %s for argument %q, got a List`, refType.isType(), nm))
 			return
 		}
 		var d, maxd uint8
 		valueType.ValidateListValues(refType, &d, &maxd, err) // m.Type is the data type of the list items
 		//
 		if maxd != refType.Depth {
-			*err = append(*err, fmt.Errorf(`Input value "%s", nested List type depth different reqired %d, got %d %s`, nm, refType.Depth, maxd, atPosition))
+			*err = append(*err, Diagf(CodeValue, atPosition, `Input value "%s", nested List type depth different reqired %d, got %d`, nm, refType.Depth, maxd))
 		}
 
 	case ObjectVals:
 		//  { name:value name:value ... } - match the name,value pairs against the refType (object type fields or input type fields)
-		fmt.Println("=========== CheckInputValueType  ObjVals ==============")
-
+		debug("check object value", logger.Type(refType.Name_.String()), logger.Any("argument", nm.String()))
 		valueType.ValidateObjectValues(refType, err)
 
 	case *EnumValue_:
 		if refType.Depth > 0 { // required type is not a LIST
-			*err = append(*err, fmt.Errorf(`List type expected, got an enum value "%s" instead for "%s" %s`, valueType.String(), nm, atPosition))
+			*err = append(*err, Diagf(CodeValue, atPosition, `List type expected, got an enum value "%s" instead for "%s"`, valueType.String(), nm))
 			return
 		}
 		// EAST WEST NORHT SOUTH
-		fmt.Println("=========== CheckInputValueType  EnumValue ==============")
 		if refType.isType() != ENUM {
-			*err = append(*err, fmt.Errorf(`"%s" is an enum like value but the argument type "%s" is not an Enum type %s`, valueType.Name, refType.Name_, atPosition))
+			*err = append(*err, Diagf(CodeValue, atPosition, `"%s" is an enum like value but the argument type "%s" is not an Enum type`, valueType.Name, refType.Name_))
 		} else {
 			valueType.CheckEnumValue(refType, err)
 		}
 
 	default:
-		fmt.Println("=========== CheckInputValueType  Default ==============")
 		// single instance data
-		fmt.Printf("valueType: %s\n", valueType)
-		fmt.Printf("name: %s\n", refType.Name_)
-		fmt.Printf("constrint: %08b\n", refType.Constraint)
-		fmt.Printf("depth: %d\n", refType.Depth)
-		fmt.Println("defType ", a.isType(), a.IsScalar())
-		fmt.Println("refType ", refType.isType())
-		fmt.Printf("a  %T\n", a.InputValueProvider)
+		debug("check value", logger.Type(refType.Name_.String()), logger.Any("argument", nm.String()), logger.Any("value", valueType), logger.Any("valueType", a.isType()))
 
 		// save default type before potential coercing
 		defType := a.isType()
@@ -198,27 +217,20 @@ func (a *InputValue_) CheckInputValueType(refType *GQLtype, nm Name_, err *[]err
 		if a.isType() == NULL {
 			// test case FieldArgListInt3_6 [int]!  null  - value cannot be null
 			if refType.Constraint>>uint(refType.Depth)&1 == 1 {
-				*err = append(*err, fmt.Errorf(`Value cannot be NULL %s`, atPosition))
+				*err = append(*err, Diagf(CodeValue, atPosition, `Value cannot be NULL`))
 			}
 
 		} else if refType.isType() == SCALAR { //a.IsScalar() {
 
 			// can the input value be coerced e.g. from string to Time
-			// try coercing default value to the appropriate scalar e.g. string to Time
-			if s, ok := refType.AST.(ScalarProvider); ok { // assert interface supported - normal assert type (*Scalar_) would also work just as well because there is only 1 scalar type really
-				if civ, cerr := s.Coerce(a.InputValueProvider); cerr != nil {
-					*err = append(*err, cerr)
-					return
-				} else {
-					a.InputValueProvider = civ
-					defType = a.isType()
-				}
+			if !a.coerceScalar(refType, atPosition, err) {
+				return
 			}
+			defType = a.isType()
 		} else if refType.Depth > 0 {
 			//
 			// coerce scalar to List of required depth
 			//
-			fmt.Println("Coerce to list")
 			// recursively create InputValue_ upto depth required.
 			var coerce2list func(d uint8) List_
 
@@ -243,10 +255,41 @@ func (a *InputValue_) CheckInputValueType(refType *GQLtype, nm Name_, err *[]err
 		}
 
 		if defType != NULL && defType != LIST && defType != refType.isType() {
-			*err = append(*err, fmt.Errorf(`Required type for argument %q is %s, got %s %s`, nm, refType.isType().String(), defType.String(), nm.AtPosition()))
+			*err = append(*err, Diagf(CodeValue, nm.Loc, `Required type for argument %q is %s, got %s`, nm, refType.isType().String(), defType.String()))
+		} else {
+			a.checkNumber(err)
 		}
+	}
+}
 
-		fmt.Println("================. CheckInputValueType. =============== end")
+// coerceScalar converts the value to the scalar type of refType using the scalar registry e.g. string to DateTime.
+// Returns false when the value is rejected by the scalar.
+func (a *InputValue_) coerceScalar(refType *GQLtype, atPosition *Loc_, err *[]error) bool {
+	s, ok := refType.AST.(ScalarProvider) // assert interface supported - normal assert type (*Scalar_) would also work just as well because there is only 1 scalar type really
+	if !ok {
+		return true
+	}
+	civ, cerr := s.Coerce(a.InputValueProvider)
+	if cerr != nil {
+		*err = append(*err, Diagf(CodeValue, atPosition, "%w", cerr))
+		return false
+	}
+	a.InputValueProvider = civ
+	return true
+}
+
+// checkNumber validates an Int value is within the 32-bit signed range and a Float value is finite, as required by the spec.
+func (a *InputValue_) checkNumber(err *[]error) {
+	switch v := a.InputValueProvider.(type) {
+	case Int_:
+		// a malformed number has already been reported by the lexer
+		if _, perr := strconv.ParseInt(string(v), 10, 32); errors.Is(perr, strconv.ErrRange) {
+			*err = append(*err, Diagf(CodeValue, a.Loc, `Int value %s is outside the 32-bit signed range`, v))
+		}
+	case Float_:
+		if f, perr := strconv.ParseFloat(string(v), 64); errors.Is(perr, strconv.ErrRange) && math.IsInf(f, 0) {
+			*err = append(*err, Diagf(CodeValue, a.Loc, `Float value %s is not a finite number`, v))
+		}
 	}
 }
 
@@ -287,9 +330,20 @@ type GQLtype struct {
 	Depth      uint8           // depth of nested List e.g. depth 2 would be [[type]]. Depth 0 implies non-list type, depth > 0 is a list type
 	Name_                      // type name. inherit AssignName(). Use Name_ to access AST via cache lookup. ALternatively, use AST above or TypeFlag_ instead of string.
 	Base       string          // base type e.g. Name_ = "Episode" has Base = E(num)
+	Span_
 	sync.Mutex
 }
 
+// Clone returns a copy of the type. The AST of the named type is not copied as it belongs to the type's own cache entry.
+func (t *GQLtype) Clone() *GQLtype {
+	if t == nil {
+		return nil
+	}
+	t.Lock()
+	defer t.Unlock()
+	return &GQLtype{Constraint: t.Constraint, AST: t.AST, Depth: t.Depth, Name_: t.Name_.Clone(), Base: t.Base, Span_: t.Span_}
+}
+
 func (t GQLtype) String() string {
 	var s strings.Builder
 	for i := uint8(0); i < t.Depth; i++ {
@@ -520,6 +574,94 @@ func (s RawString_) String() string {
 	return string(s)
 }
 
+// quoteString returns s as a single line string, escaped so the lexer reads it back as s.
+func quoteString(s string) string {
+	var b strings.Builder
+	b.WriteString(token.STRINGDEL)
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteString(token.STRINGDEL)
+	return b.String()
+}
+
+// printableAsBlockString reports whether s can be printed as a block string and read back unchanged, which excludes
+// control characters, leading or trailing blank lines and indentation that would be removed as common indentation.
+func printableAsBlockString(s string) bool {
+	if len(s) == 0 {
+		return false
+	}
+	for _, r := range s {
+		if (r < 0x20 && r != '\t' && r != '\n') || r == 0x7f {
+			return false
+		}
+	}
+	lines := strings.Split(s, "\n")
+	if isBlankLine(lines[0]) || isBlankLine(lines[len(lines)-1]) {
+		return false
+	}
+	if indentedLines(lines) && (lines[0][0] == ' ' || lines[0][0] == '\t') {
+		return false
+	}
+	return true
+}
+
+// blockString returns s as a block string. s must satisfy printableAsBlockString.
+func blockString(s string) string {
+	var b strings.Builder
+	b.WriteString(token.RAWSTRINGDEL)
+	if indentedLines(strings.Split(s, "\n")) {
+		// a leading blank line makes the first line part of the common indentation, which is then zero
+		b.WriteString("\n")
+	}
+	b.WriteString(strings.ReplaceAll(s, token.RAWSTRINGDEL, `\`+token.RAWSTRINGDEL))
+	if strings.HasSuffix(s, token.STRINGDEL) || strings.HasSuffix(s, `\`) {
+		// keep the last character from reading as part of the closing delimiter
+		b.WriteString("\n")
+	}
+	b.WriteString(token.RAWSTRINGDEL)
+	return b.String()
+}
+
+// indentedLines reports whether all non-blank lines after the first are indented, so would have their
+// common indentation removed when read as a block string.
+func indentedLines(lines []string) bool {
+	if len(lines) < 2 {
+		return false
+	}
+	for _, l := range lines[1:] {
+		if !isBlankLine(l) && l[0] != ' ' && l[0] != '\t' {
+			return false
+		}
+	}
+	return true
+}
+
+func isBlankLine(l string) bool {
+	return len(strings.Trim(l, " \t")) == 0
+}
+
 type Bool_ bool //bool
 
 func (b Bool_) ValueNode() {}
@@ -538,6 +680,17 @@ func (b Bool_) String() string {
 
 type List_ []*InputValue_
 
+func (l List_) Clone() List_ {
+	if l == nil {
+		return nil
+	}
+	c := make(List_, len(l))
+	for i, v := range l {
+		c[i] = v.Clone()
+	}
+	return c
+}
+
 func (l List_) ValueNode() {}
 func (l List_) IsType() TypeFlag_ {
 	return LIST
@@ -592,7 +745,7 @@ func (l List_) Exists() bool {
 func (l List_) ValidateListValues(iv *GQLtype, d *uint8, maxd *uint8, err *[]error) {
 	reqType := iv.isType() // INT, FLOAT, OBJECT, PET, MEASURE etc            note: OBJECT is for specification of a type, OBJECTVAL is an object literal for input purposes
 	reqDepth := iv.Depth
-	fmt.Println("ValidateListValues...............length, reqType, reqDepth, d, maxd = ", len(l), reqType, reqDepth, *d, *maxd)
+	debug("validate list values", logger.Type(iv.Name_.String()), logger.Any("length", len(l)), logger.Any("depth", *d))
 	// is reqType a valid type if not abort
 	if reqType == ILLEGAL {
 		return
@@ -609,44 +762,48 @@ func (l List_) ValidateListValues(iv *GQLtype, d *uint8, maxd *uint8, err *[]err
 		switch in := v.InputValueProvider.(type) {
 
 		case List_:
-			fmt.Println("++++++++ ValidateListValues List_ ++++++++")
 			// maxd records maximum depth of list(d=1) [] list of lists [[]](d=2) = [[][][][]] list of lists of lists (d=3) [[[]]] = [[[][][]],[[][][][]],[[]]]
-			fmt.Printf("** in is %T\n ", in)
-			fmt.Println("about to ValidateListValue ")
 			in.ValidateListValues(iv, d, maxd, err)
 			*d--
 
 		case ObjectVals:
-			fmt.Println("++++++++ ValidateListValues ObjectVals ++++++++")
 			// default values in input object form { name:value name:value ... }: []*ArgumentT type ArgumentT: struct {Name_, InputValueProvider}
 			// reqType is the type of the input object  - which defines the name and associated type for each item in the { }
 			if *d != reqDepth {
 				if reqDepth == 0 {
-					*err = append(*err, fmt.Errorf(`Value %s should not be contained in a List %s`, v, v.AtPosition()))
+					*err = append(*err, Diagf(CodeValue, v.Loc, `Value %s should not be contained in a List`, v))
 				} else {
-					*err = append(*err, fmt.Errorf(`Value %s is not at required nesting of %d %s`, v, reqDepth, v.AtPosition()))
+					*err = append(*err, Diagf(CodeValue, v.Loc, `Value %s is not at required nesting of %d`, v, reqDepth))
 				}
 			}
 			in.ValidateObjectValues(iv, err)
 
+		case Variable_:
+			// checked against the variable's definition
+
 		default:
-			fmt.Println("++++++++ ValidateListValues Default ++++++++")
 			// check the item - this is matched against the type specification for the list ie. [type]
 			if *d != reqDepth && v.isType() != NULL {
 				if reqDepth == 0 {
-					*err = append(*err, fmt.Errorf(`Value %s should not be contained in a List %s`, v, v.AtPosition()))
+					*err = append(*err, Diagf(CodeValue, v.Loc, `Value %s should not be contained in a List`, v))
 				} else {
-					*err = append(*err, fmt.Errorf(`Value %s is not at required nesting of %d %s`, v, reqDepth, v.AtPosition()))
+					*err = append(*err, Diagf(CodeValue, v.Loc, `Value %s is not at required nesting of %d`, v, reqDepth))
 				}
 			}
+			// coerce each element as for a single value e.g. ["2020-01-01T00:00:00Z"] to [DateTime]
+			if reqType == SCALAR && v.isType() != NULL && !v.coerceScalar(iv, v.Loc, err) {
+				continue
+			}
 			if t := v.isType(); t != reqType {
 				if v.isType() == NULL {
 					if iv.Constraint>>uint(iv.Depth-*d)&1 == 1 { // is not-null constraint set
-						*err = append(*err, fmt.Errorf(`List cannot contain NULLs %s`, v.AtPosition()))
+						*err = append(*err, Diagf(CodeValue, v.Loc, `List cannot contain NULLs`))
 					}
 				} else {
-					*err = append(*err, fmt.Errorf(`Required type "%s", got "%s" %s`, reqType, t, v.AtPosition()))
+					*err = append(*err, Diagf(CodeValue, v.Loc, `Required type "%s", got "%s"`, reqType, t))
 				}
+			} else {
+				v.checkNumber(err)
 			}
 		}
 	}
@@ -680,6 +837,10 @@ func (d *DirectiveT) CoerceDirectiveName() {
 	d.Name_.Name = NameValue_("@" + d.Name_.String())
 }
 
+func (d *DirectiveT) Clone() *DirectiveT {
+	return &DirectiveT{Name_: d.Name_.Clone(), Arguments_: d.Arguments_.Clone()}
+}
+
 // ========== Directives ================
 
 // Directives_, attribute in many GQLtype e.g EnumValue, Interface, Union, Input,...
@@ -688,16 +849,23 @@ type Directives_ struct {
 	Directives []*DirectiveT
 }
 
+func (d Directives_) Clone() Directives_ {
+	if d.Directives == nil {
+		return Directives_{}
+	}
+	c := make([]*DirectiveT, len(d.Directives))
+	for i, v := range d.Directives {
+		c[i] = v.Clone()
+	}
+	return Directives_{Directives: c}
+}
+
 //func (d *Directives_) CheckDirectiveLocation(location string, err *[]error) {}
 
+// AppendDirective accepts repeated use of a directive. Whether the directive is repeatable is known only once its
+// definition has been resolved, so duplicates are rejected during validation, see checkRepeatable_.
 func (d *Directives_) AppendDirective(s *DirectiveT) error {
 	s.CoerceDirectiveName()
-	for _, v := range d.Directives {
-		if v.Name_.String() == s.Name_.String() {
-			loc := s.Name_.Loc
-			return fmt.Errorf(`Duplicate Directive name "%s" at line: %d, column: %d`, s.Name_.String(), loc.Line, loc.Column)
-		}
-	}
 	d.Directives = append(d.Directives, s)
 	return nil
 }
@@ -755,22 +923,35 @@ func (d *Directives_) CheckInputValueType(err *[]error) {
 				// verify arguments in an instance of a directive d, against directive STATEMENT definition
 				for _, arg := range v.Arguments {
 					var found bool
-					fmt.Printf("arg: %#v\n", arg)
 					for _, ivdef = range dir.ArgumentDefs {
-						fmt.Printf("arg.Name %s, ivdef.Name_ %s\n", arg.Name_, ivdef.Name_)
 						if arg.Name_.Equals(ivdef.Name_) {
 							found = true
 							break
 						}
 					}
 					if !found {
-						*err = append(*err, fmt.Errorf(`Argument "%s" is %w "%s" %s`, arg.Name, DirectiveErr, dir.Name, arg.Name_.AtPosition()))
+						*err = append(*err, Diagf(CodeDirective, arg.Name_.Loc, `Argument "%s" is %w "%s"`, arg.Name, DirectiveErr, dir.Name))
 						//	*err = append(*err, fmt.Errorf(`Argument %q is not a valid name %q %s`, arg.Name, dir.Name, arg.Name_.AtPosition()))
 					} else {
 						// verify argument input value
 						arg.Value.CheckInputValueType(ivdef.Type, arg.Name_, err)
 					}
 				}
+				// required arguments i.e. non-null without a default
+				for _, ivdef := range dir.ArgumentDefs {
+					if ivdef.Type.IsNullable() || ivdef.DefaultVal != nil {
+						continue
+					}
+					var found bool
+					for _, arg := range v.Arguments {
+						if arg.Name_.Equals(ivdef.Name_) {
+							found = true
+						}
+					}
+					if !found {
+						*err = append(*err, Diagf(CodeDirective, v.Name_.Loc, `Required argument "%s" of directive "%s" is missing`, ivdef.Name, dir.Name))
+					}
+				}
 			}
 		}
 	}
@@ -798,17 +979,16 @@ func (d *Directives_) SolicitAbstractTypes(unresolved UnresolvedMap) {
 func (d *Directives_) CheckDirectiveRef(dir NameValue_, err *[]error) {
 	for _, v := range d.Directives {
 		if v.Name_.String() == dir.String() {
-			*err = append(*err, fmt.Errorf(`Directive "%s" references itself, is not permitted %s`, dir, v.Name_.AtPosition()))
+			*err = append(*err, Diagf(CodeDirective, v.Name_.Loc, `Directive "%s" references itself, is not permitted`, dir))
 		}
 	}
 }
 
 func (d *Directives_) checkDirectiveLocation_(input DirectiveLoc, err *[]error) {
 	var found bool
-	fmt.Println("++++++++++++++++ checkDirectiveLocation_ +++++++++++++++++++++++++")
 	for _, v := range d.Directives {
 		//	get the use named directive's AST
-		fmt.Println("++ v.Name: ", v.Name.String())
+		debug("check directive location", logger.Any("directive", v.Name.String()), logger.Any("location", input))
 		if e, ok := TyCache[v.Name.String()]; ok {
 			found = false
 			if x, ok := e.(*Directive_); ok {
@@ -819,13 +999,30 @@ func (d *Directives_) checkDirectiveLocation_(input DirectiveLoc, err *[]error)
 				}
 				if !found {
 					if dloc, ok := DirectiveLocationMap[input]; ok {
-						*err = append(*err, fmt.Errorf(`Directive "%s" is not registered for %s usage %s`, v.Name, dloc, v.Name_.AtPosition()))
+						*err = append(*err, Diagf(CodeDirective, v.Name_.Loc, `Directive "%s" is not registered for %s usage`, v.Name, dloc))
 					} else {
-						*err = append(*err, fmt.Errorf(`System Error: Directive %s not found in map `, v.Name, dloc, v.Name_.AtPosition()))
+						*err = append(*err, Diagf(CodeInternal, v.Name_.Loc, `System Error: Directive %s not found in map`, v.Name))
 					}
 				}
 			} else {
-				*err = append(*err, fmt.Errorf(`AST for type %s is not a Directive_ type %s`, v.Name, v.Name_.AtPosition()))
+				*err = append(*err, Diagf(CodeDirective, v.Name_.Loc, `AST for type %s is not a Directive_ type`, v.Name))
+			}
+		}
+	}
+	d.checkRepeatable_(err)
+}
+
+// checkRepeatable_ rejects a second use of a directive at the same location unless its definition is repeatable.
+// A directive whose definition is not cached is not repeatable.
+func (d *Directives_) checkRepeatable_(err *[]error) {
+	for i, v := range d.Directives {
+		if x, ok := TyCache[v.Name.String()].(*Directive_); ok && x.Repeatable {
+			continue
+		}
+		for _, prev := range d.Directives[:i] {
+			if prev.Name_.Equals(v.Name_) {
+				*err = append(*err, Diagf(CodeDuplicate, v.Name_.Loc, `Duplicate Directive name "%s"`, v.Name_.String()).WithRelated("first used here", prev.Name_.Loc))
+				break
 			}
 		}
 	}
@@ -836,9 +1033,23 @@ func (d *Directives_) checkDirectiveLocation_(input DirectiveLoc, err *[]error)
 type Loc_ struct {
 	Line   int
 	Column int
+	Offset int    // byte offset from the start of the source
+	File   string // source of the document, empty when it is not named
+	End    Pos    // position immediately after the token at the location, zero if not known
+}
+
+func (l *Loc_) Clone() *Loc_ {
+	if l == nil {
+		return nil
+	}
+	c := *l
+	return &c
 }
 
 func (l Loc_) String() string {
+	if len(l.File) > 0 {
+		return "at line: " + strconv.Itoa(l.Line) + " " + "column: " + strconv.Itoa(l.Column) + " in " + l.File
+	}
 	return "at line: " + strconv.Itoa(l.Line) + " " + "column: " + strconv.Itoa(l.Column)
 	//return "" + strconv.Itoa(l.Line) + " " + strconv.Itoa(l.Column) + "] "
 }
@@ -849,6 +1060,83 @@ type NameAssigner interface {
 	AssignName(name string, loc *Loc_, errS *[]error)
 }
 
+// ============== DescAssigner  ========================
+
+// DescAssigner is satisfied by all nodes that accept a description i.e. "..." or """...""" preceding the definition.
+type DescAssigner interface {
+	AssignDesc(desc string)
+}
+
+// writeDesc prints the description, if any, ahead of its definition. A multi-line description (or one containing a double quote) is
+// printed as a block string, otherwise as a single line string.
+func writeDesc(s *strings.Builder, desc string) {
+	if len(desc) == 0 {
+		return
+	}
+	if (strings.Contains(desc, "\n") || strings.Contains(desc, token.STRINGDEL)) && printableAsBlockString(desc) {
+		s.WriteString(blockString(desc))
+	} else {
+		s.WriteString(quoteString(desc))
+	}
+	s.WriteString("\n")
+}
+
+// ============== CommentAssigner  ========================
+
+// CommentAssigner is satisfied by all nodes that keep the # comments of a document, when the lexer is in comment mode
+// (see lexer.KeepComments). Comments on the lines before a definition lead it, a comment that follows it on the same line trails it.
+type CommentAssigner interface {
+	AssignComments(leading []string, trailing []string)
+}
+
+// Comments_ holds the text, after the #, of the comments attached to a definition. The trailing comments of a statement
+// are those from its last definition to the end of its closing line, e.g. a commented out field.
+type Comments_ struct {
+	Leading  []string
+	Trailing []string
+}
+
+func (c *Comments_) AssignComments(leading []string, trailing []string) {
+	c.Leading = append(c.Leading, leading...)
+	c.Trailing = append(c.Trailing, trailing...)
+}
+
+func (c Comments_) Clone() Comments_ {
+	return Comments_{Leading: append([]string(nil), c.Leading...), Trailing: append([]string(nil), c.Trailing...)}
+}
+
+// writeLeading prints the leading comments, each on its own line, ahead of the description.
+func (c *Comments_) writeLeading(s *strings.Builder) {
+	if len(c.Leading) == 0 {
+		return
+	}
+	for _, v := range c.Leading {
+		s.WriteString("\n" + token.COMMENT + v)
+	}
+	s.WriteString("\n")
+}
+
+// writeTrailing prints the trailing comments after the definition. Each is terminated by a newline so it cannot
+// comment out the text that follows.
+func (c *Comments_) writeTrailing(s *strings.Builder) {
+	for _, v := range c.Trailing {
+		s.WriteString(" " + token.COMMENT + v + "\n")
+	}
+}
+
+// writeBody prints the {...} body of a statement with the trailing comments of the statement ahead of the closing brace,
+// so they read back as the statement's rather than leading the next statement.
+func (c *Comments_) writeBody(s *strings.Builder, body string) {
+	if len(c.Trailing) == 0 || !strings.HasSuffix(body, token.RBRACE) {
+		s.WriteString(body)
+		c.writeTrailing(s)
+		return
+	}
+	s.WriteString(strings.TrimSuffix(body, token.RBRACE))
+	c.writeTrailing(s)
+	s.WriteString(token.RBRACE)
+}
+
 // ===============  NameValue_  =========================
 
 type NameValue_ string
@@ -872,6 +1160,10 @@ type Name_ struct {
 	Loc  *Loc_
 }
 
+func (n Name_) Clone() Name_ {
+	return Name_{Name: n.Name, Loc: n.Loc.Clone()}
+}
+
 func (n Name_) String() string {
 	return string(n.Name)
 }
@@ -911,6 +1203,49 @@ type Document struct {
 	Statements    []GQLTypeProvider
 	StatementsMap map[NameValue_]GQLTypeProvider
 	ErrorMap      map[NameValue_][]error
+	Results       []StatementResult // outcome of each statement, in document order
+	Deprecations  []Deprecation     // deprecated members of the statements applied, in document order
+}
+
+// Outcome is what applying a statement does, or in a dry run would do, to the stored document.
+type Outcome uint8
+
+const (
+	_         Outcome = iota
+	Created           // no statement of the name is stored
+	Updated           // the statement replaces the stored statement of the same name
+	Unchanged         // the statement is the same as the stored statement, so is not written
+	Rejected          // the statement has errors, so is not written
+	Skipped           // the statement references a statement of the document that is rejected or skipped, or validation of the document stopped at the error limit, so is not written
+)
+
+func (o Outcome) String() string {
+	switch o {
+	case Created:
+		return "created"
+	case Updated:
+		return "updated"
+	case Unchanged:
+		return "unchanged"
+	case Rejected:
+		return "rejected"
+	case Skipped:
+		return "skipped"
+	}
+	return "unknown"
+}
+
+// StatementResult reports the outcome of a statement of a document. The revision and times are those of the
+// statement stored once the document is applied, or in a dry run would be stored. They are zero when no statement
+// of the name is stored.
+type StatementResult struct {
+	Name     NameValue_
+	Type     string // statement type e.g. Object, Enum
+	Outcome  Outcome
+	Errors   []error   // why the statement was rejected
+	Revision int       // one for the first version of the statement, incremented each time it is written
+	Inserted time.Time // when the first version was written
+	Updated  time.Time // when the statement was last rewritten, zero if it has not been
 }
 
 func (d Document) String() string {
@@ -945,13 +1280,19 @@ const (
 )
 
 type Schema_ struct {
+	Desc string
 	Directives_
+	Span_
 	Query        Name_ // named type to use as root type of query into graph of types e.g. "Query" -> type Query { allPersons(last : Int ) : [Person!]! }
 	Mutation     Name_
 	Subscription Name_
 	Op           opType //  current operation used during parsing of statement
 }
 
+func (sc *Schema_) Clone() *Schema_ {
+	return &Schema_{Desc: sc.Desc, Directives_: sc.Directives_.Clone(), Span_: sc.Span_, Query: sc.Query.Clone(), Mutation: sc.Mutation.Clone(), Subscription: sc.Subscription.Clone()}
+}
+
 func (sc *Schema_) TypeSystemNode() {}
 
 func (sc *Schema_) Type() string {
@@ -969,14 +1310,39 @@ func (sc *Schema_) AssignName(s string, loc *Loc_, errS *[]error) {
 	}
 }
 
+// SolicitAbstractTypes records the root operation types as well as the directives, so each root type is resolved.
+func (sc *Schema_) SolicitAbstractTypes(unresolved UnresolvedMap) {
+	sc.Directives_.SolicitAbstractTypes(unresolved)
+	for _, v := range sc.RootTypes() {
+		unresolved[v] = nil
+	}
+}
+
+// RootTypes returns the root operation types defined by the schema, in query, mutation, subscription order.
+func (sc *Schema_) RootTypes() []Name_ {
+	var r []Name_
+	for _, v := range []Name_{sc.Query, sc.Mutation, sc.Subscription} {
+		if v.Exists() {
+			r = append(r, v)
+		}
+	}
+	return r
+}
+
 func (sc *Schema_) CheckDirectiveLocation(err *[]error) {
 	sc.checkDirectiveLocation_(SCHEMA_DL, err)
 }
 
+func (sc *Schema_) AssignDesc(desc string) {
+	sc.Desc = desc
+}
+
 func (sc *Schema_) String() string {
 	var s strings.Builder
-	sc.Directives_.String()
-	s.WriteString("schema {")
+	writeDesc(&s, sc.Desc)
+	s.WriteString("schema ")
+	s.WriteString(sc.Directives_.String())
+	s.WriteString("{")
 	if sc.Query.Exists() {
 		s.WriteString("\nquery : ")
 		s.WriteString(sc.Query.String())
@@ -1000,21 +1366,21 @@ func (sc *Schema_) TypeName() NameValue_ {
 // ======================================================
 
 var blank string = ""
-var errNameChar string = "Invalid character in identifer at line: %d, column: %d"
-var errNameBegin string = "identifer %q cannot start with two underscores at line: %d, column: %d"
+var errNameChar string = "Invalid character in identifer"
+var errNameBegin string = "identifer %q cannot start with two underscores"
 
 func ValidateName(name string, errS *[]error, loc *Loc_) {
 	// /[_A-Za-z][_0-9A-Za-z]*/
 	var err error
 	if len(name) == 0 {
-		err = fmt.Errorf("Error: zero length name passed to ValidateName")
+		err = Diagf(CodeInternal, nil, "Error: zero length name passed to ValidateName")
 		*errS = append(*errS, err)
 		return
 	}
 
 	ch, _ := utf8.DecodeRuneInString(name[:1])
 	if unicode.IsDigit(ch) {
-		err = fmt.Errorf("identifier cannot start with a number at line: %d, column: %d", loc.Line, loc.Column)
+		err = Diagf(CodeName, loc, "identifier cannot start with a number")
 		*errS = append(*errS, err)
 	}
 
@@ -1022,12 +1388,12 @@ func ValidateName(name string, errS *[]error, loc *Loc_) {
 		switch i {
 		case 0:
 			if !(v == '_' || (v >= 'A' || v <= 'Z') || (v >= 'a' && v <= 'z')) {
-				err = fmt.Errorf(errNameChar, loc.Line, loc.Column)
+				err = Diagf(CodeName, loc, errNameChar)
 				*errS = append(*errS, err)
 			}
 		default:
 			if !((v >= '0' && v <= '9') || (v >= 'A' || v <= 'Z') || (v >= 'a' && v <= 'z') || v == '_') {
-				err = fmt.Errorf(errNameChar, loc.Line, loc.Column)
+				err = Diagf(CodeName, loc, errNameChar)
 				*errS = append(*errS, err)
 			}
 		}
@@ -1037,7 +1403,7 @@ func ValidateName(name string, errS *[]error, loc *Loc_) {
 	}
 
 	if len(name) > 1 && name[:2] == "__" {
-		err = fmt.Errorf(errNameBegin, name, loc.Line, loc.Column)
+		err = Diagf(CodeName, loc, errNameBegin, name)
 		*errS = append(*errS, err)
 	}
 }
@@ -0,0 +1,92 @@
+package ast
+
+import (
+	"time"
+)
+
+// DefaultDeprecationReason is the reason of a @deprecated directive that does not give one.
+const DefaultDeprecationReason = "No longer supported"
+
+// Member is a field, argument, input field or enum value of a type. Its path identifies it within the type
+// e.g. "age" for a field and "age(unit)" for an argument of the field.
+type Member struct {
+	Kind       string // "Field", "Argument", "Input Field" or "Enum Value"
+	Path       string
+	Directives *Directives_
+}
+
+// Deprecation is a member of a type that is marked @deprecated.
+type Deprecation struct {
+	Type     NameValue_
+	Kind     string // as Member
+	Path     string // as Member
+	Reason   string
+	Revision int       // revision of the type in which the member was deprecated, zero when not recorded for a stored type
+	Since    time.Time // when the revision deprecating the member was written, zero when not recorded for a stored type
+}
+
+// Members returns the members of the type in definition order.
+func Members(stmt GQLTypeProvider) []Member {
+	var m []Member
+	args := func(path string, a InputValueDefs) {
+		for _, v := range a {
+			m = append(m, Member{Kind: "Argument", Path: path + "(" + v.Name.String() + ")", Directives: &v.Directives_})
+		}
+	}
+	fields := func(fs FieldSet) {
+		for _, v := range fs {
+			m = append(m, Member{Kind: "Field", Path: v.Name.String(), Directives: &v.Directives_})
+			args(v.Name.String(), v.ArgumentDefs)
+		}
+	}
+	switch x := stmt.(type) {
+	case *Object_:
+		fields(x.FieldSet)
+	case *Interface_:
+		fields(x.FieldSet)
+	case *Input_:
+		for _, v := range x.InputValueDefs {
+			m = append(m, Member{Kind: "Input Field", Path: v.Name.String(), Directives: &v.Directives_})
+		}
+	case *Enum_:
+		for _, v := range x.Values {
+			m = append(m, Member{Kind: "Enum Value", Path: v.Name.String(), Directives: &v.Directives_})
+		}
+	case *Directive_:
+		args("", x.ArgumentDefs)
+	}
+	return m
+}
+
+// Deprecations returns the members of the type marked @deprecated, with the reason given by the directive.
+func Deprecations(stmt GQLTypeProvider) []Deprecation {
+	var d []Deprecation
+	for _, m := range Members(stmt) {
+		if reason, ok := m.Directives.Deprecated(); ok {
+			d = append(d, Deprecation{Type: stmt.TypeName(), Kind: m.Kind, Path: m.Path, Reason: reason})
+		}
+	}
+	return d
+}
+
+// Deprecated reports whether the directives include @deprecated, and its reason.
+func (d *Directives_) Deprecated() (string, bool) {
+	for _, v := range d.Directives {
+		if v.Name != "@deprecated" {
+			continue
+		}
+		for _, a := range v.Arguments {
+			if a.Name != "reason" || a.Value == nil {
+				continue
+			}
+			switch x := a.Value.InputValueProvider.(type) {
+			case String_:
+				return string(x), true
+			case RawString_:
+				return string(x), true
+			}
+		}
+		return DefaultDeprecationReason, true
+	}
+	return "", false
+}
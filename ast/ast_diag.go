@@ -0,0 +1,144 @@
+package ast
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// ======================  Diagnostic =========================
+
+// ErrCode categorises a Diagnostic. Codes are stable and can be relied on by tools e.g. editors and CI annotations.
+type ErrCode string
+
+const (
+	CodeSyntax     ErrCode = "SYNTAX"        // document does not conform to the grammar
+	CodeName       ErrCode = "INVALID_NAME"  // name violates the naming rules
+	CodeDuplicate  ErrCode = "DUPLICATE"     // name defined more than once e.g. field, argument, enum value
+	CodeType       ErrCode = "TYPE"          // referenced type does not exist or cannot be used in its position
+	CodeValue      ErrCode = "INVALID_VALUE" // value does not match its type
+	CodeDirective  ErrCode = "DIRECTIVE"     // directive not defined, repeated or used at the wrong location
+	CodeImplements ErrCode = "IMPLEMENTS"    // object or interface does not satisfy an interface it implements
+	CodeExtend     ErrCode = "EXTEND"        // invalid type extension
+	CodeExecutable ErrCode = "EXECUTABLE"    // operation or fragment is not valid against the schema
+	CodeDatabase   ErrCode = "DATABASE"      // type could not be read from or written to the database
+	CodeDependent  ErrCode = "DEPENDENT"     // change invalidates a stored type that depends on the changed type
+	CodeDeprecated ErrCode = "DEPRECATED"    // member removed from a stored type before the deprecation policy permits
+	CodeInternal   ErrCode = "INTERNAL"      // unexpected condition
+)
+
+type Severity uint8
+
+const (
+	SevError Severity = iota
+	SevWarning
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SevWarning:
+		return "warning"
+	}
+	return "error"
+}
+
+// Pos is a position in a source. Offset is the byte offset from the start of the source.
+type Pos struct {
+	Line   int
+	Column int
+	Offset int
+}
+
+// Span is the range of a source that a Diagnostic applies to. End equals Start when only the start is known.
+type Span struct {
+	File  string
+	Start Pos
+	End   Pos
+}
+
+// IsZero reports whether the span has no location.
+func (s Span) IsZero() bool {
+	return s.Start.Line == 0
+}
+
+func (s Span) String() string {
+	if len(s.File) > 0 {
+		return s.File + ":" + strconv.Itoa(s.Start.Line) + ":" + strconv.Itoa(s.Start.Column)
+	}
+	return strconv.Itoa(s.Start.Line) + ":" + strconv.Itoa(s.Start.Column)
+}
+
+// SpanOf returns the span of a location i.e. of the token at the location, zero if loc is nil.
+func SpanOf(loc *Loc_) Span {
+	if loc == nil {
+		return Span{}
+	}
+	p := Pos{Line: loc.Line, Column: loc.Column, Offset: loc.Offset}
+	if loc.End.Line == 0 {
+		return Span{File: loc.File, Start: p, End: p}
+	}
+	return Span{File: loc.File, Start: p, End: loc.End}
+}
+
+// Span_ records the source range of a node, from the start of its first token to the end of its last,
+// e.g. the whole of a type reference "[Int!]!" or of a statement including its description.
+type Span_ struct {
+	Span Span
+}
+
+// SpanAssigner is satisfied by the nodes that record their source range.
+type SpanAssigner interface {
+	AssignSpan(s Span)
+}
+
+func (s *Span_) AssignSpan(sp Span) {
+	s.Span = sp
+}
+
+// Related is a secondary location of a Diagnostic e.g. the first definition of a duplicate name.
+type Related struct {
+	Message string
+	Span    Span
+}
+
+// Diagnostic is the error type reported by the parser and the ast checks. Its Error() text includes the location, so it
+// reads the same as an error built with fmt.Errorf, while tools use the fields instead.
+//
+// A category error, such as parser.TypeResolveErr, that is wrapped by the message (%w) is
+// available to errors.Is via Unwrap.
+type Diagnostic struct {
+	Code     ErrCode
+	Severity Severity
+	Message  string     // excludes the location
+	Span     Span       // zero if the error has no location
+	TypeName NameValue_ // statement that owns the error, if known
+	Related  []Related
+	err      error // message as an error, which may wrap a category error
+}
+
+// Diagf returns a Diagnostic of severity error. The format and arguments are as for fmt.Errorf, including %w.
+func Diagf(code ErrCode, loc *Loc_, format string, a ...interface{}) *Diagnostic {
+	err := fmt.Errorf(format, a...)
+	return &Diagnostic{Code: code, Severity: SevError, Message: err.Error(), Span: SpanOf(loc), err: err}
+}
+
+// WithRelated adds a secondary location to the Diagnostic.
+func (d *Diagnostic) WithRelated(msg string, loc *Loc_) *Diagnostic {
+	d.Related = append(d.Related, Related{Message: msg, Span: SpanOf(loc)})
+	return d
+}
+
+func (d *Diagnostic) Error() string {
+	if d.Span.IsZero() {
+		return d.Message
+	}
+	loc := Loc_{Line: d.Span.Start.Line, Column: d.Span.Start.Column, File: d.Span.File}
+	return d.Message + " " + loc.String()
+}
+
+func (d *Diagnostic) Unwrap() error {
+	if d.err == nil {
+		return nil
+	}
+	return errors.Unwrap(d.err)
+}
@@ -0,0 +1,312 @@
+package ast
+
+import (
+	"strings"
+)
+
+// ======================  Executable Document =========================
+// ExecutableDocument
+//		ExecutableDefinition-list
+// ExecutableDefinition
+//		OperationDefinition
+//		FragmentDefinition
+
+// ExecDocument holds the operations and fragments of a client request. Unlike the type system
+// Document it is never persisted.
+type ExecDocument struct {
+	Operations []*OperationStmt
+	Fragments  []*FragmentStmt
+}
+
+func (d *ExecDocument) String() string {
+	var s strings.Builder
+	for _, v := range d.Operations {
+		s.WriteString(v.String())
+		s.WriteString("\n")
+	}
+	for _, v := range d.Fragments {
+		s.WriteString(v.String())
+		s.WriteString("\n")
+	}
+	return s.String()
+}
+
+// ======================  Selection Set =========================
+// SelectionSet
+//		{ Selection-list }
+// Selection
+//		Field
+//		FragmentSpread
+//		InlineFragment
+
+// SelectionSetI is satisfied by each member of a selection set i.e. *Field, *FragmentSpread and *InlineFragment.
+type SelectionSetI interface {
+	SelectionNode()
+	String() string
+}
+
+// SelectionAppender is satisfied by all nodes that contain a selection set.
+type SelectionAppender interface {
+	AppendSelection(s SelectionSetI)
+}
+
+type SelectionSet []SelectionSetI
+
+func (s *SelectionSet) AppendSelection(x SelectionSetI) {
+	*s = append(*s, x)
+}
+
+func (s SelectionSet) Clone() SelectionSet {
+	if s == nil {
+		return nil
+	}
+	c := make(SelectionSet, len(s))
+	for i, v := range s {
+		switch x := v.(type) {
+		case *Field:
+			c[i] = x.Clone()
+		case *FragmentSpread:
+			c[i] = x.Clone()
+		case *InlineFragment:
+			c[i] = x.Clone()
+		}
+	}
+	return c
+}
+
+func (s SelectionSet) String() string {
+	if len(s) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("{\n")
+	for _, v := range s {
+		b.WriteString(v.String())
+		b.WriteString("\n")
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// ======================  Operation =========================
+// OperationDefinition
+//		OperationType Name-opt VariableDefinitions-opt Directives-opt SelectionSet
+//		SelectionSet
+type OperationStmt struct {
+	OpType       string // query, mutation or subscription. The query shorthand, { ... }, is a query.
+	Name_               // optional. An anonymous operation holds only the location of the operation.
+	VariableDefs []*VariableDef
+	Directives_
+	SelectionSet
+}
+
+func (o *OperationStmt) Type() string {
+	return "Operation"
+}
+
+func (o *OperationStmt) Clone() *OperationStmt {
+	c := &OperationStmt{OpType: o.OpType, Name_: o.Name_.Clone(), Directives_: o.Directives_.Clone(), SelectionSet: o.SelectionSet.Clone()}
+	if o.VariableDefs != nil {
+		c.VariableDefs = make([]*VariableDef, len(o.VariableDefs))
+		for i, v := range o.VariableDefs {
+			c.VariableDefs[i] = v.Clone()
+		}
+	}
+	return c
+}
+
+func (o *OperationStmt) String() string {
+	var s strings.Builder
+	s.WriteString(o.OpType)
+	if o.Name_.Exists() {
+		s.WriteString(" " + o.Name_.String())
+	}
+	if len(o.VariableDefs) > 0 {
+		s.WriteString("(")
+		for i, v := range o.VariableDefs {
+			if i > 0 {
+				s.WriteString(" ")
+			}
+			s.WriteString(v.String())
+		}
+		s.WriteString(")")
+	}
+	s.WriteString(" ")
+	s.WriteString(o.Directives_.String())
+	s.WriteString(o.SelectionSet.String())
+	return s.String()
+}
+
+// ======================  VariableDef =========================
+// VariableDefinitions
+//		( VariableDefinition-list )
+// VariableDefinition
+//		Variable : Type DefaultValue-opt Directives-opt
+type VariableDef struct {
+	Name_      // excludes the $
+	Type       *GQLtype
+	DefaultVal *InputValue_
+	Directives_
+}
+
+func (v *VariableDef) AssignType(t *GQLtype) {
+	v.Type = t
+}
+
+func (v *VariableDef) Clone() *VariableDef {
+	return &VariableDef{Name_: v.Name_.Clone(), Type: v.Type.Clone(), DefaultVal: v.DefaultVal.Clone(), Directives_: v.Directives_.Clone()}
+}
+
+func (v *VariableDef) String() string {
+	var s strings.Builder
+	s.WriteString("$" + v.Name_.String() + ": " + v.Type.String())
+	if v.DefaultVal != nil {
+		s.WriteString(" = " + v.DefaultVal.String())
+	}
+	if v.Directives_.Len() > 0 {
+		s.WriteString(" " + v.Directives_.String())
+	}
+	return s.String()
+}
+
+// ======================  Variable_ =========================
+
+// Variable_ is an input value that references a variable of the operation e.g. $episode
+type Variable_ struct {
+	Name_ // excludes the $
+}
+
+func (v Variable_) ValueNode() {}
+func (v Variable_) IsType() TypeFlag_ {
+	return VARIABLE
+}
+
+func (v Variable_) String() string {
+	return "$" + v.Name_.String()
+}
+
+// ======================  Field =========================
+// Field
+//		Alias-opt Name Arguments-opt Directives-opt SelectionSet-opt
+// Alias
+//		Name :
+type Field struct {
+	Alias Name_ // optional
+	Name_
+	Arguments_
+	Directives_
+	SelectionSet
+}
+
+func (f *Field) SelectionNode() {}
+
+// AssignName accepts the introspection fields e.g. __typename, whose names are otherwise reserved.
+func (f *Field) AssignName(s string, loc *Loc_, errS *[]error) {
+	if strings.HasPrefix(s, "__") {
+		f.Name_ = Name_{Name: NameValue_(s), Loc: loc}
+		return
+	}
+	f.Name_.AssignName(s, loc, errS)
+}
+
+// ResponseKey is the key of the field in the response i.e. its alias if given, otherwise its name.
+func (f *Field) ResponseKey() NameValue_ {
+	if f.Alias.Exists() {
+		return f.Alias.Name
+	}
+	return f.Name
+}
+
+func (f *Field) Clone() *Field {
+	return &Field{Alias: f.Alias.Clone(), Name_: f.Name_.Clone(), Arguments_: f.Arguments_.Clone(), Directives_: f.Directives_.Clone(), SelectionSet: f.SelectionSet.Clone()}
+}
+
+func (f *Field) String() string {
+	var s strings.Builder
+	if f.Alias.Exists() {
+		s.WriteString(f.Alias.String() + ": ")
+	}
+	s.WriteString(f.Name_.String())
+	s.WriteString(f.Arguments_.String())
+	if f.Directives_.Len() > 0 {
+		s.WriteString(" " + f.Directives_.String())
+	}
+	if len(f.SelectionSet) > 0 {
+		s.WriteString(" " + f.SelectionSet.String())
+	}
+	return s.String()
+}
+
+// ======================  FragmentSpread =========================
+// FragmentSpread
+//		... FragmentName Directives-opt
+type FragmentSpread struct {
+	Name_
+	Directives_
+}
+
+func (f *FragmentSpread) SelectionNode() {}
+
+func (f *FragmentSpread) Clone() *FragmentSpread {
+	return &FragmentSpread{Name_: f.Name_.Clone(), Directives_: f.Directives_.Clone()}
+}
+
+func (f *FragmentSpread) String() string {
+	return "..." + f.Name_.String() + " " + f.Directives_.String()
+}
+
+// ======================  InlineFragment =========================
+// InlineFragment
+//		... TypeCondition-opt Directives-opt SelectionSet
+type InlineFragment struct {
+	TypeCond Name_ // optional
+	Directives_
+	SelectionSet
+	Loc *Loc_ // location of the ...
+}
+
+func (f *InlineFragment) SelectionNode() {}
+
+func (f *InlineFragment) Clone() *InlineFragment {
+	return &InlineFragment{TypeCond: f.TypeCond.Clone(), Directives_: f.Directives_.Clone(), SelectionSet: f.SelectionSet.Clone(), Loc: f.Loc.Clone()}
+}
+
+func (f *InlineFragment) String() string {
+	var s strings.Builder
+	s.WriteString("...")
+	if f.TypeCond.Exists() {
+		s.WriteString(" on " + f.TypeCond.String())
+	}
+	s.WriteString(" ")
+	s.WriteString(f.Directives_.String())
+	s.WriteString(f.SelectionSet.String())
+	return s.String()
+}
+
+// ======================  Fragment =========================
+// FragmentDefinition
+//		fragment FragmentName TypeCondition Directives-opt SelectionSet
+// TypeCondition
+//		on NamedType
+type FragmentStmt struct {
+	Name_
+	TypeCond Name_
+	Directives_
+	SelectionSet
+}
+
+func (f *FragmentStmt) Type() string {
+	return "Fragment"
+}
+
+func (f *FragmentStmt) Clone() *FragmentStmt {
+	return &FragmentStmt{Name_: f.Name_.Clone(), TypeCond: f.TypeCond.Clone(), Directives_: f.Directives_.Clone(), SelectionSet: f.SelectionSet.Clone()}
+}
+
+func (f *FragmentStmt) String() string {
+	var s strings.Builder
+	s.WriteString("fragment " + f.Name_.String() + " on " + f.TypeCond.String() + " ")
+	s.WriteString(f.Directives_.String())
+	s.WriteString(f.SelectionSet.String())
+	return s.String()
+}
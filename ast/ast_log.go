@@ -0,0 +1,21 @@
+package ast
+
+import (
+	"github.com/rosshpayne/graph-sdl/logger"
+)
+
+// logr receives the debug entries of validation. It is shared by all parsers, as the type cache is, and is set by
+// the parser, see parser.(*Parser).SetLogger.
+var logr logger.Logger = logger.Nop
+
+// SetLogger sets the logger of the package. A nil logger silences logging.
+func SetLogger(l logger.Logger) {
+	if l == nil {
+		l = logger.Nop
+	}
+	logr = l
+}
+
+func debug(msg string, fields ...logger.Field) {
+	logr.Log(logger.Debug, msg, fields...)
+}
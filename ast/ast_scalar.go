@@ -0,0 +1,220 @@
+package ast
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ScalarFuncs define the values of a custom scalar. Parse converts an input value, typically a String_, to the
+// scalar's internal value. Validate, if set, checks the internal value. Serialize, if set, converts the internal value
+// back to an input value, which is how the scalar is printed. Without Serialize the input value is printed unchanged.
+type ScalarFuncs struct {
+	Parse     func(v InputValueProvider) (interface{}, error)
+	Validate  func(v interface{}) error
+	Serialize func(v interface{}) (InputValueProvider, error)
+}
+
+var (
+	scalarsL sync.RWMutex
+	scalars  = make(map[string]ScalarFuncs)
+)
+
+// RegisterScalar registers the functions of the custom scalar of the name, replacing any registered for it.
+// A value of a custom scalar that is not registered is accepted as is.
+func RegisterScalar(name string, f ScalarFuncs) error {
+	switch name {
+	case "Int", "Float", "String", "Boolean", "ID":
+		return fmt.Errorf(`"%s" is a built-in scalar and cannot be registered`, name)
+	}
+	if len(name) == 0 || f.Parse == nil {
+		return fmt.Errorf(`A scalar requires a name and a Parse function`)
+	}
+	scalarsL.Lock()
+	scalars[name] = f
+	scalarsL.Unlock()
+	return nil
+}
+
+// UnregisterScalar removes the functions registered for the custom scalar of the name, so its values are accepted as is.
+// The built-in custom scalars e.g. DateTime cannot be removed.
+func UnregisterScalar(name string) error {
+	if _, ok := builtinScalars[name]; ok {
+		return fmt.Errorf(`"%s" is a built-in custom scalar and cannot be unregistered`, name)
+	}
+	scalarsL.Lock()
+	delete(scalars, name)
+	scalarsL.Unlock()
+	return nil
+}
+
+// LookupScalar returns the functions registered for the custom scalar of the name.
+func LookupScalar(name string) (ScalarFuncs, bool) {
+	scalarsL.RLock()
+	defer scalarsL.RUnlock()
+	f, ok := scalars[name]
+	return f, ok
+}
+
+// ==================== built-in custom scalars ====================
+
+var builtinScalars = map[string]ScalarFuncs{
+	"DateTime": {Parse: parseDateTime, Serialize: formatTime(time.RFC3339Nano)},
+	"Date":     {Parse: parseDate, Serialize: formatTime("2006-01-02")},
+	"JSON":     {Parse: parseJSON, Serialize: serializeString},
+	"URL":      {Parse: parseURL, Serialize: serializeString},
+	"UUID":     {Parse: parseUUID, Serialize: serializeString},
+	"BigInt":   {Parse: parseBigInt, Serialize: serializeBigInt},
+	"Email":    {Parse: parseEmail, Serialize: serializeString},
+	"Time":     {Parse: parseTime, Serialize: formatTime(time.RFC3339)},
+}
+
+func init() {
+	for name, f := range builtinScalars {
+		scalars[name] = f
+	}
+}
+
+// stringValue returns the value of a String_ or RawString_ input value.
+func stringValue(scalar string, v InputValueProvider) (string, error) {
+	switch x := v.(type) {
+	case String_:
+		return string(x), nil
+	case RawString_:
+		return string(x), nil
+	}
+	return "", fmt.Errorf("%s value must be a string", scalar)
+}
+
+func parseDateTime(v InputValueProvider) (interface{}, error) {
+	s, err := stringValue("DateTime", v)
+	if err != nil {
+		return nil, err
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return nil, fmt.Errorf(`Invalid DateTime value "%s", expected an RFC 3339 date-time`, s)
+	}
+	return t, nil
+}
+
+func parseDate(v InputValueProvider) (interface{}, error) {
+	s, err := stringValue("Date", v)
+	if err != nil {
+		return nil, err
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return nil, fmt.Errorf(`Invalid Date value "%s", expected YYYY-MM-DD`, s)
+	}
+	return t, nil
+}
+
+// parseTime accepts the layouts of the original Time scalar.
+func parseTime(v InputValueProvider) (interface{}, error) {
+	s, err := stringValue("Time", v)
+	if err != nil {
+		return nil, err
+	}
+	for _, layout := range []string{"Jan 2, 2006 at 3:04pm (MST)", "2006-Jan-02", time.RFC3339} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return nil, fmt.Errorf(`Invalid Time value "%s", expected "Jan 2, 2006 at 3:04pm (MST)", "2006-Jan-02" or an RFC 3339 date-time`, s)
+}
+
+func formatTime(layout string) func(v interface{}) (InputValueProvider, error) {
+	return func(v interface{}) (InputValueProvider, error) {
+		return String_(v.(time.Time).Format(layout)), nil
+	}
+}
+
+func parseJSON(v InputValueProvider) (interface{}, error) {
+	s, err := stringValue("JSON", v)
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	if err := json.Compact(&b, []byte(s)); err != nil {
+		return nil, fmt.Errorf(`Invalid JSON value: %s`, err)
+	}
+	return json.RawMessage(b.Bytes()), nil
+}
+
+func parseURL(v InputValueProvider) (interface{}, error) {
+	s, err := stringValue("URL", v)
+	if err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(s)
+	if err != nil || !u.IsAbs() || len(u.Host) == 0 {
+		return nil, fmt.Errorf(`Invalid URL value "%s", expected an absolute URL`, s)
+	}
+	return u, nil
+}
+
+var uuidRe = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+func parseUUID(v InputValueProvider) (interface{}, error) {
+	s, err := stringValue("UUID", v)
+	if err != nil {
+		return nil, err
+	}
+	if !uuidRe.MatchString(s) {
+		return nil, fmt.Errorf(`Invalid UUID value "%s"`, s)
+	}
+	return strings.ToLower(s), nil
+}
+
+// parseBigInt accepts an Int value of any size, or a string holding one.
+func parseBigInt(v InputValueProvider) (interface{}, error) {
+	var s string
+	switch x := v.(type) {
+	case Int_:
+		s = string(x)
+	default:
+		var err error
+		if s, err = stringValue("BigInt", v); err != nil {
+			return nil, fmt.Errorf("BigInt value must be an integer or a string")
+		}
+	}
+	b, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return nil, fmt.Errorf(`Invalid BigInt value "%s"`, s)
+	}
+	return b, nil
+}
+
+func serializeBigInt(v interface{}) (InputValueProvider, error) {
+	return Int_(v.(*big.Int).String()), nil
+}
+
+func parseEmail(v InputValueProvider) (interface{}, error) {
+	s, err := stringValue("Email", v)
+	if err != nil {
+		return nil, err
+	}
+	// a bare address only i.e. no display name
+	if a, err := mail.ParseAddress(s); err != nil || a.Address != s {
+		return nil, fmt.Errorf(`Invalid Email value "%s"`, s)
+	}
+	return s, nil
+}
+
+func serializeString(v interface{}) (InputValueProvider, error) {
+	switch x := v.(type) {
+	case json.RawMessage:
+		return String_(x), nil
+	case fmt.Stringer:
+		return String_(x.String()), nil
+	}
+	return String_(fmt.Sprint(v)), nil
+}
@@ -5,9 +5,9 @@ import (
 	"fmt"
 	"log"
 	"strings"
-	"time"
 
 	"github.com/rosshpayne/graph-sdl/internal/token"
+	"github.com/rosshpayne/graph-sdl/logger"
 )
 
 type TypeFlag_ uint8
@@ -32,6 +32,7 @@ const (
 	LIST
 	INTERFACE
 	UNION
+	VARIABLE
 	//
 	ILLEGAL
 )
@@ -68,6 +69,8 @@ func (tf TypeFlag_) String() string {
 		return token.INTERFACE
 	case UNION:
 		return token.UNION
+	case VARIABLE:
+		return "Variable"
 
 	}
 	return token.ILLEGAL
@@ -163,7 +166,6 @@ func init() {
 
 func IsInputType(t *GQLtype) bool {
 	// determine inputType from t.Name
-	fmt.Println("***** IsInputType ***** ", t.Name)
 	if t.IsScalar() {
 		return true
 	}
@@ -221,6 +223,10 @@ type ArgumentT struct {
 	Value *InputValue_
 }
 
+func (a *ArgumentT) Clone() *ArgumentT {
+	return &ArgumentT{Name_: a.Name_.Clone(), Value: a.Value.Clone()}
+}
+
 func (a *ArgumentT) StmtType() string {
 	return ""
 } // to support ql.NameI
@@ -236,6 +242,17 @@ func (a *ArgumentT) String(last bool) string {
 
 type ArgumentS []*ArgumentT // same as type ObjectVals []*ArgumentT
 
+func (a ArgumentS) Clone() ArgumentS {
+	if a == nil {
+		return nil
+	}
+	c := make(ArgumentS, len(a))
+	for i, v := range a {
+		c[i] = v.Clone()
+	}
+	return c
+}
+
 func (a ArgumentS) String() string {
 	var s strings.Builder
 	if len(a) > 0 {
@@ -255,6 +272,10 @@ type Arguments_ struct {
 	Arguments []*ArgumentT
 }
 
+func (a Arguments_) Clone() Arguments_ {
+	return Arguments_{Arguments: ArgumentS(a.Arguments).Clone()}
+}
+
 // func (a *Arguments_) CheckInputValueType(err *) {
 // }
 
@@ -289,6 +310,10 @@ func (a *Arguments_) SolicitAbstractTypes(unresolved UnresolvedMap) {
 
 type ObjectVals []*ArgumentT
 
+func (o ObjectVals) Clone() ObjectVals {
+	return ObjectVals(ArgumentS(o).Clone())
+}
+
 func (o ObjectVals) TypeSystemNode() {}
 func (o ObjectVals) ValueNode()      {}
 
@@ -325,8 +350,7 @@ func (o ObjectVals) Exists() bool {
 func (o ObjectVals) ValidateObjectValues(ref *GQLtype, err *[]error) {
 	//
 	var errObj string
-	fmt.Println(" ----------- ValidateObjectValues ------------------")
-	fmt.Printf("ref:  %s %T  Name:  %s   List: %v\n", ref.IsType(), ref, ref.Name_, ref.IsList())
+	debug("validate object values", logger.Type(ref.Name_.String()), logger.Any("list", ref.IsList()))
 	refFields := make(map[NameValue_]*GQLtype)
 	//
 	// What is the reference type the objectValue value should match
@@ -343,10 +367,9 @@ func (o ObjectVals) ValidateObjectValues(ref *GQLtype, err *[]error) {
 		}
 		errObj = "Object"
 	default:
-		*err = append(*err, fmt.Errorf(`Mismatched types. The input data (object values in this case) does not match a Object or Input type. The reference type is a %s`, ref.TypeName())) //TODO location required
+		*err = append(*err, Diagf(CodeValue, nil, `Mismatched types. The input data (object values in this case) does not match a Object or Input type. The reference type is a %s`, ref.TypeName())) //TODO location required
 		return
 	}
-	fmt.Println("****** ", refFields)
 	//
 	// loop thru name:value pairs using the ref type (object or Input types) to match against name and its associated type for each pair.
 	//
@@ -354,19 +377,18 @@ func (o ObjectVals) ValidateObjectValues(ref *GQLtype, err *[]error) {
 
 		// reference type of the object/input field
 		if reftype, ok := refFields[v.Name]; !ok {
-			*err = append(*err, fmt.Errorf(`field "%s" does not exist in type %s  %s`, v.Name, ref.TypeName(), v.AtPosition()))
-
+			*err = append(*err, Diagf(CodeValue, v.Loc, `field "%s" does not exist in type %s`, v.Name, ref.TypeName()))
+
+		} else if _, ok := v.Value.InputValueProvider.(Variable_); ok {
+			// checked against the variable's definition
+			continue
+		} else if reftype.isType2() == SCALAR {
+			// a scalar field is coerced and checked as for a single argument value e.g. {when: "2020-01-01"} to DateTime
+			v.Value.CheckInputValueType(reftype, v.Name_, err)
 		} else {
 			// compare reference type against field  data
 			//	fmt.Printf("Field, , v.Value.isType(), refType.isType2(): %s, %T %T, %s, %s, %s\n", v.Name, v.Value, reftype, v.Value.isType(), reftype.isType2(), reftype.isType()) // InputValue.isType, *GQLtype.isType()
-			fmt.Println("++++++++++++")
-			fmt.Printf("Field v.Name:  [%s]\n", v.Name)
-			fmt.Printf("v.Value:       [%T]\n", v.Value)
-			fmt.Printf("v.value.isType(): %s\n", v.Value.isType())
-			fmt.Printf("reftype.isType2():  %s\n", reftype.isType2())
-			fmt.Printf("reftype.isType():   %s\n", reftype.isType())
-			fmt.Printf("reftype:        %T\n", reftype)
-			fmt.Println("++++++++++++")
+			debug("validate object field", logger.Type(ref.Name_.String()), logger.Any("field", v.Name), logger.Any("valueType", v.Value.isType()), logger.Any("fieldType", reftype.isType2()))
 			// value == LIST isType2 == LIST isType == INT	    // LIST appropriate but no check for internal types made in ValidateListValues.
 			// value == LIST isTYpe2 == INT  isType == INT		// should not be in list
 			// value == INT  isType2 == LIST isType = INT       // must be in list
@@ -376,11 +398,11 @@ func (o ObjectVals) ValidateObjectValues(ref *GQLtype, err *[]error) {
 				// only LIST differences consider here
 				// value is a LIST but ref type is not
 				if v.Value.isType() == LIST && !reftype.isList() {
-					*err = append(*err, fmt.Errorf(`%s "%s" from type "%s" should not be a List type %s`, errObj, v.Name, ref.Name, v.Value.AtPosition()))
+					*err = append(*err, Diagf(CodeValue, v.Value.Loc, `%s "%s" from type "%s" should not be a List type`, errObj, v.Name, ref.Name))
 					// abort any further validation on this item
 					return
 				} else if v.Value.isType() != LIST && reftype.isType2() == LIST {
-					*err = append(*err, fmt.Errorf(`%s "%s" from type "%s" expected %s %s`, errObj, v.Name, ref.Name, reftype.isType2(), v.Value.AtPosition()))
+					*err = append(*err, Diagf(CodeValue, v.Value.Loc, `%s "%s" from type "%s" expected %s`, errObj, v.Name, ref.Name, reftype.isType2()))
 				}
 			}
 			// when value not LIST check types
@@ -389,7 +411,7 @@ func (o ObjectVals) ValidateObjectValues(ref *GQLtype, err *[]error) {
 				// for the purpose of this validation OBJECT and INPUT are the same
 				if !(v.Value.isType() == OBJECT && reftype.isType() == INPUT) {
 					//	if v.Value.isType() != LIST {
-					*err = append(*err, fmt.Errorf(`%s "%s" from type "%s" expected %s got %s %s`, errObj, v.Name, ref.Name, reftype.isType(), v.Value.isType(), v.Value.AtPosition()))
+					*err = append(*err, Diagf(CodeValue, v.Value.Loc, `%s "%s" from type "%s" expected %s got %s`, errObj, v.Name, ref.Name, reftype.isType(), v.Value.isType()))
 				}
 			}
 			//
@@ -398,26 +420,26 @@ func (o ObjectVals) ValidateObjectValues(ref *GQLtype, err *[]error) {
 			switch iv := v.Value.InputValueProvider.(type) { // y inob:Float_
 
 			case List_:
-				fmt.Println(" ----------- ValidateObjectValues --LIST----------------")
 				// maxd records maximum depth of list(d=1) [] list of lists [[]](d=2) = [[][][][]] list of lists of lists (d=3) [[[]]] = [[[][][]],[[][][][]],[[]]]
 				var d, maxd uint8
 				iv.ValidateListValues(reftype, &d, &maxd, err)
 				d--
 				if maxd != reftype.Depth && reftype.Depth != 0 { // reftype.Depth == 0 check performed above
-					*err = append(*err, fmt.Errorf(`Argument "%s", nested List type depth different reqired %d, got %d %s`, v.Name, reftype.Depth, maxd, v.AtPosition()))
+					*err = append(*err, Diagf(CodeValue, v.Loc, `Argument "%s", nested List type depth different reqired %d, got %d`, v.Name, reftype.Depth, maxd))
 				}
 
 			case ObjectVals:
-				fmt.Println(" ----------- ValidateObjectValues ------OBJVAL------------")
 				iv.ValidateObjectValues(reftype, err)
 
+			default:
+				v.Value.checkNumber(err)
 			}
 		}
 	}
 	//
 	// check mandatory fields present - for Input types only.
 	//
-	var at string
+	var at *Loc_
 	if _, ok := ref.AST.(*Input_); ok {
 		for k, v := range refFields { // k Name, v *Type
 			if (v.Constraint>>uint(v.Depth))&1 == 1 { // mandatory field. Check present.
@@ -426,10 +448,10 @@ func (o ObjectVals) ValidateObjectValues(ref *GQLtype, err *[]error) {
 					if v.Name == k {
 						found = true
 					}
-					at = v.AtPosition()
+					at = v.Loc
 				}
 				if !found {
-					*err = append(*err, fmt.Errorf(`Mandatory field "%s" missing in type "%s" %s `, k, ref.TypeName(), at))
+					*err = append(*err, Diagf(CodeValue, at, `Mandatory field "%s" missing in type "%s"`, k, ref.TypeName()))
 				}
 			}
 		}
@@ -440,11 +462,180 @@ func (o ObjectVals) ValidateObjectValues(ref *GQLtype, err *[]error) {
 // Slice of Name_
 type NameS []Name_
 
+func (f NameS) Clone() NameS {
+	if f == nil {
+		return nil
+	}
+	c := make(NameS, len(f))
+	for i, v := range f {
+		c[i] = v.Clone()
+	}
+	return c
+}
+
 // SolicitAbstractTypes is typically promoted to type that embedds the NameS type.
 func (f NameS) SolicitAbstractTypes(unresolved UnresolvedMap) { //TODO rename to checkUnresolvedTypes
 	//  handled by type in which NameS is nested
 }
 
+func (f NameS) Contains(nm NameValue_) bool {
+	for _, v := range f {
+		if v.Name.Equals(nm) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *NameS) appendImplements(nm Name_) error {
+	if f.Contains(nm.Name) {
+		return Diagf(CodeDuplicate, nm.Loc, "Duplicate interface name")
+	}
+	*f = append(*f, nm)
+	return nil
+}
+
+func (f NameS) String() string {
+	var s strings.Builder
+	for i, v := range f {
+		if i == 0 {
+			s.WriteString(" implements ")
+		}
+		if i > 0 {
+			s.WriteString(" & ")
+		}
+		s.WriteString(v.String())
+	}
+	return s.String()
+}
+
+// ImplementsAppender is satisfied by the types that can implement interfaces i.e. Object_ and Interface_
+type ImplementsAppender interface {
+	AppendImplements(nm Name_) error
+}
+
+// checkImplements verifies each named interface in implements exists as an interface type, that any interface implemented by
+// that interface is also declared by the implementing type and that fs is a valid implementation of the interface's fields, see checkFields:
+// each field is present with a covariant type and the arguments of the interface field. kind is used in error messages e.g. "Type" or "Interface".
+func checkImplements(kind string, name Name_, implements NameS, fs FieldSet, err *[]error) {
+	for _, v := range implements {
+		var (
+			ok   bool
+			itf_ GQLTypeProvider
+		)
+		// interface does not exist - this error has been reported during type resolution
+		if itf_, ok = TyCache[v.Name.String()]; !ok {
+			continue
+		}
+		itf, ok := itf_.(*Interface_)
+		if !ok {
+			*err = append(*err, Diagf(CodeImplements, v.Loc, `"%s" is not an interface type`, v.Name))
+			continue
+		}
+		// transitive rule - interfaces implemented by the interface must also be declared by the implementing type
+		for _, anc := range itf.Implements {
+			if !anc.Name.Equals(name.Name) && !implements.Contains(anc.Name) {
+				*err = append(*err, Diagf(CodeImplements, v.Loc, `%s "%s" must also implement interface "%s" as it is implemented by interface "%s"`, kind, name, anc, itf.Name_))
+			}
+		}
+		checkFields(kind, name, fs, itf, err)
+	}
+}
+
+// checkFields applies the spec's IsValidImplementation field rules for interface itf to the fields fs of the implementing type.
+// Missing fields are reported together, while each field type, argument or extra argument that does not conform is reported
+// at the implementing field.
+func checkFields(kind string, name Name_, fs FieldSet, itf *Interface_, err *[]error) {
+	var missing strings.Builder
+	for _, ifn := range itf.FieldSet { // interface fields
+		var fn *Field_
+		for _, v := range fs { // implementing type fields
+			if v.Name.Equals(ifn.Name) {
+				fn = v
+				break
+			}
+		}
+		if fn == nil {
+			missing.WriteString(` "`)
+			missing.WriteString(ifn.Name.String())
+			missing.WriteString(`"`)
+			continue
+		}
+		if !IsValidImplementationFieldType(fn.Type, ifn.Type) {
+			*err = append(*err, Diagf(CodeImplements, fn.Name_.Loc, `Field "%s" of %s "%s" has type "%s" which does not implement type "%s" of interface "%s"`, fn.Name, strings.ToLower(kind), name, fn.Type, ifn.Type, itf.Name_))
+		}
+		// interface arguments must be present with the same type
+		for _, ia := range ifn.ArgumentDefs {
+			var fa *InputValueDef
+			for _, v := range fn.ArgumentDefs {
+				if v.Name.Equals(ia.Name) {
+					fa = v
+					break
+				}
+			}
+			if fa == nil {
+				*err = append(*err, Diagf(CodeImplements, fn.Name_.Loc, `Field "%s" of %s "%s" is missing argument "%s" of interface "%s"`, fn.Name, strings.ToLower(kind), name, ia.Name, itf.Name_))
+				continue
+			}
+			if !fa.Type.Equals(ia.Type) {
+				*err = append(*err, Diagf(CodeImplements, fa.Name_.Loc, `Argument "%s" of field "%s" of %s "%s" has type "%s", expected "%s" as in interface "%s"`, fa.Name, fn.Name, strings.ToLower(kind), name, fa.Type, ia.Type, itf.Name_))
+			}
+		}
+		// arguments not defined by the interface must be optional
+		for _, fa := range fn.ArgumentDefs {
+			if ifn.ArgumentDefs.Contains(fa.Name) {
+				continue
+			}
+			if !fa.Type.IsNullable() && fa.DefaultVal == nil {
+				*err = append(*err, Diagf(CodeImplements, fa.Name_.Loc, `Argument "%s" of field "%s" of %s "%s" must be optional as it is not defined by interface "%s"`, fa.Name, fn.Name, strings.ToLower(kind), name, itf.Name_))
+			}
+		}
+	}
+	if missing.Len() > 0 {
+		*err = append(*err, Diagf(CodeImplements, nil, `%s "%s" does not implement interface "%s", missing %s`, kind, name, itf.Name_, missing.String()))
+	}
+}
+
+// IsValidImplementationFieldType reports whether field type t is a valid implementation of interface field type it i.e. it
+// is the same type, or is covariant with it: a stricter non-null, or a named type that implements the interface or is
+// a member of the union named by it.
+func IsValidImplementationFieldType(t, it *GQLtype) bool {
+	if t == nil || it == nil {
+		return false
+	}
+	if t.Depth != it.Depth {
+		return false
+	}
+	// each level made non-null by the interface must be non-null in the implementation
+	for i := uint(0); i <= uint(t.Depth); i++ {
+		if (it.Constraint>>i)&1 == 1 && (t.Constraint>>i)&1 == 0 {
+			return false
+		}
+	}
+	return isSubType(t.Name, it.Name)
+}
+
+// isSubType reports whether named type a is, implements or is a member of named type b.
+func isSubType(a, b NameValue_) bool {
+	if a.Equals(b) {
+		return true
+	}
+	switch x := TyCache[b.String()].(type) {
+	case *Union_:
+		if _, ok := TyCache[a.String()].(*Object_); ok {
+			return x.NameS.Contains(a)
+		}
+	case *Interface_:
+		switch y := TyCache[a.String()].(type) {
+		case *Object_:
+			return y.Implements.Contains(b)
+		case *Interface_:
+			return y.Implements.Contains(b)
+		}
+	}
+	return false
+}
+
 type SDLSelectionSetter interface {
 	GetSelectionSet() FieldSet
 	TypeName() NameValue_
@@ -462,6 +653,8 @@ type SDLSelectionSetter interface {
 //         FieldDefinition:
 //			Description-opt Name ArgumentsDefinition- opt : Type Directives-Con
 type Object_ struct {
+	Comments_
+	Span_
 	Desc        string
 	Name_             // instane name of Object_ type e.g. Person, Pet. inherits fields and method, AssignName from Name_. Overidden
 	Implements  NameS //TODO  = create type NameS []*Name_ and add method AppendField to NameS and then embedded this type in Object_ struct
@@ -472,6 +665,11 @@ type Object_ struct {
 
 func (o *Object_) TypeSystemNode() {}
 
+// Clone returns a deep copy of the type, used to apply an extension without changing the cached original.
+func (o *Object_) Clone() *Object_ {
+	return &Object_{Desc: o.Desc, Comments_: o.Comments_.Clone(), Span_: o.Span_, Name_: o.Name_.Clone(), Implements: o.Implements.Clone(), Directives_: o.Directives_.Clone(), FieldSet: o.FieldSet.Clone()}
+}
+
 func (o *Object_) Type() string {
 	return "Object"
 }
@@ -495,57 +693,15 @@ func (o *Object_) CheckDirectiveRef(dirName NameValue_, err *[]error) {
 
 func (o *Object_) CheckDirectiveLocation(err *[]error) {
 	o.checkDirectiveLocation_(OBJECT_DL, err)
+	o.FieldSet.CheckDirectiveLocation(err)
 }
 
 func (f *Object_) CheckImplements(err *[]error) {
-	for _, v := range f.Implements {
-		var (
-			ok   bool
-			itf_ GQLTypeProvider
-		)
-		// check name represents a interface type in ast
-		// TODO - requires fetchInterface to use cache - rethink - MAYBE SHOULD NOT BE A OBJECT METHOD but a check in the parser itself as it has access to the cache.
-		fmt.Printf("CACHE LOOPKUP: Look for interface %s in cache\n", v.Name)
-		if itf_, ok = TyCache[v.Name.String()]; !ok {
-			return
-		}
-		// check object implements the interface
-		if itf_.Type() != "Interface" {
-			*err = append(*err, fmt.Errorf(`"%s" is not an interface type, %s`, v.Name, v.AtPosition()))
-			return
-		}
-		itf := itf_.(*Interface_)
-		satisfied := make(map[NameValue_]bool)
-		for _, v := range itf.FieldSet {
-			fmt.Println()
-			satisfied[v.Name] = false
-		}
-		for _, ifn := range itf.FieldSet { // interface fields
-			for _, fn := range f.FieldSet { // object fields
-				if ifn.Name_.String() == fn.Name_.String() {
-					if ifn.Type.Equals(fn.Type) {
-						satisfied[fn.Name] = true
-					}
-				}
-			}
-		}
-		//
-		// publish in repeatable order because maps cannot
-		//
-		var s strings.Builder
-		for _, ifn := range itf.FieldSet { // interface fields
-			if v, ok := satisfied[ifn.Name]; ok {
-				if !v {
-					s.WriteString(` "`)
-					s.WriteString(ifn.Name.String())
-					s.WriteString(`"`)
-				}
-			}
-		}
-		if len(s.String()) > 0 {
-			*err = append(*err, fmt.Errorf(`Type "%s" does not implement interface "%s", missing %s`, f.Name_, itf.Name_, s.String()))
-		}
-	}
+	checkImplements("Type", f.Name_, f.Implements, f.FieldSet, err)
+}
+
+func (f *Object_) AppendImplements(nm Name_) error {
+	return f.Implements.appendImplements(nm)
 }
 
 func (o *Object_) SolicitAbstractTypes(unresolved UnresolvedMap) {
@@ -563,7 +719,7 @@ func (o *Object_) SolicitAbstractTypes(unresolved UnresolvedMap) {
 func (f *Object_) CheckIsOutputType(err *[]error) {
 	for _, v := range f.FieldSet {
 		if !IsOutputType(v.Type) {
-			*err = append(*err, fmt.Errorf(`Field "%s" type "%s", is not an output type %s`, v.Name_, v.Type.Name, v.Type.Name_.AtPosition()))
+			*err = append(*err, Diagf(CodeType, v.Type.Name_.Loc, `Field "%s" type "%s", is not an output type`, v.Name_, v.Type.Name))
 		}
 	}
 
@@ -574,7 +730,7 @@ func (f *Object_) CheckIsInputType(err *[]error) {
 	for _, v := range f.FieldSet {
 		for _, p := range v.ArgumentDefs {
 			if !IsInputType(p.Type) {
-				*err = append(*err, fmt.Errorf(`Argument "%s" type "%s", is not an input type %s`, p.Name_, p.Type.Name, p.Type.Name_.AtPosition()))
+				*err = append(*err, Diagf(CodeType, p.Type.Name_.Loc, `Argument "%s" type "%s", is not an input type`, p.Name_, p.Type.Name))
 			}
 		}
 	}
@@ -590,20 +746,19 @@ func (f *Object_) AssignName(s string, loc *Loc_, unresolved *[]error) {
 	f.Name_.AssignName(s, loc, unresolved) // assign Name_{Name, Loc} and addErr if error found
 }
 
+func (f *Object_) AssignDesc(desc string) {
+	f.Desc = desc
+}
+
 func (f *Object_) String() string {
 	var s strings.Builder
-	s.WriteString("\ntype " + f.Name_.String())
-	for i, v := range f.Implements {
-		if i == 0 {
-			s.WriteString(" implements ")
-		}
-		if i > 0 {
-			s.WriteString(" & ")
-		}
-		s.WriteString(v.String())
-	}
+	s.WriteString("\n")
+	f.writeLeading(&s)
+	writeDesc(&s, f.Desc)
+	s.WriteString("type " + f.Name_.String())
+	s.WriteString(f.Implements.String())
 	s.WriteString(" " + f.Directives_.String())
-	s.WriteString(f.FieldSet.String())
+	f.writeBody(&s, f.FieldSet.String())
 
 	return s.String()
 }
@@ -612,6 +767,17 @@ func (f *Object_) String() string {
 
 type FieldSet []*Field_
 
+func (f FieldSet) Clone() FieldSet {
+	if f == nil {
+		return nil
+	}
+	c := make(FieldSet, len(f))
+	for i, v := range f {
+		c[i] = v.Clone()
+	}
+	return c
+}
+
 func (f *FieldSet) String() string {
 	var s strings.Builder
 	for i, v := range *f {
@@ -626,6 +792,14 @@ func (f *FieldSet) String() string {
 	return s.String()
 }
 
+// CheckDirectiveLocation checks the directives of each field and its arguments, as Enum_ and Input_ do for their members.
+// It is also where a directive repeated on a field is rejected, see checkRepeatable_.
+func (fs *FieldSet) CheckDirectiveLocation(err *[]error) {
+	for _, v := range *fs {
+		v.CheckDirectiveLocation(err)
+	}
+}
+
 func (fs *FieldSet) CheckInputValueType(err *[]error) {
 	for _, v := range *fs {
 		v.CheckInputValueType(err)
@@ -643,12 +817,7 @@ func (fs *FieldSet) AppendField(f_ *Field_) error {
 		// check field (Name and Type) not already present
 		//if v.Equals(f_) { // TODO - where is it necessary to compare Name & Type
 		if v.Name.String() == f_.Name.String() {
-			loc := f_.Name_.Loc
-			if loc != nil {
-				return fmt.Errorf(`Duplicate Field name "%s" at line: %d, column: %d`, f_.Name_, loc.Line, loc.Column)
-			} else {
-				return fmt.Errorf(`Duplicate Field name "%s" at line: %d, column: %d`, f_.Name_)
-			}
+			return Diagf(CodeDuplicate, f_.Name_.Loc, `Duplicate Field name "%s"`, f_.Name_).WithRelated("first defined here", v.Name_.Loc)
 		}
 	}
 	*fs = append(*fs, f_)
@@ -665,6 +834,8 @@ type AssignTyper interface {
 // FieldDefinition
 //		 Description-opt	Name	ArgumentsDefinition-opt	:	Type	Directives-opt
 type Field_ struct {
+	Comments_
+	Span_
 	Desc string
 	Name_
 	ArgumentDefs InputValueDefs //[]*InputValueDef []*ObjectVal
@@ -675,6 +846,10 @@ type Field_ struct {
 
 //TODO  - check argumentsDefs
 
+func (f *Field_) Clone() *Field_ {
+	return &Field_{Desc: f.Desc, Comments_: f.Comments_.Clone(), Span_: f.Span_, Name_: f.Name_.Clone(), ArgumentDefs: f.ArgumentDefs.Clone(), Type: f.Type.Clone(), Directives_: f.Directives_.Clone()}
+}
+
 func (f *Field_) AssignType(t *GQLtype) {
 	f.Type = t
 }
@@ -705,10 +880,17 @@ func (f *Field_) AssignName(s string, loc *Loc_, unresolved *[]error) {
 	f.Name_.AssignName(s, loc, unresolved) // assign Name_{Name, Loc} and addErr if error found
 }
 
+func (f *Field_) AssignDesc(desc string) {
+	f.Desc = desc
+}
+
 func (f *Field_) String() string {
 	var encl [2]token.TokenType = [2]token.TokenType{token.LPAREN, token.RPAREN}
 	var s strings.Builder
-	s.WriteString("\n" + f.Name_.String())
+	s.WriteString("\n")
+	f.writeLeading(&s)
+	writeDesc(&s, f.Desc)
+	s.WriteString(f.Name_.String())
 	s.WriteString(f.ArgumentDefs.String(encl))
 	s.WriteString(" : ")
 	// GQLtype lock
@@ -717,6 +899,7 @@ func (f *Field_) String() string {
 	f.Type.Unlock()
 	s.WriteString(" ")
 	s.WriteString(f.Directives_.String())
+	f.writeTrailing(&s)
 	return s.String()
 }
 
@@ -749,11 +932,31 @@ func (f *Field_) CheckInputValueType(err *[]error) {
 // Slice of *InputValueDef
 type InputValueDefs []*InputValueDef
 
+func (f InputValueDefs) Clone() InputValueDefs {
+	if f == nil {
+		return nil
+	}
+	c := make(InputValueDefs, len(f))
+	for i, v := range f {
+		c[i] = v.Clone()
+	}
+	return c
+}
+
+func (fa InputValueDefs) Contains(nm NameValue_) bool {
+	for _, v := range fa {
+		if v.Name.Equals(nm) {
+			return true
+		}
+	}
+	return false
+}
+
 func (fa *InputValueDefs) AppendField(f *InputValueDef, unresolved *[]error) {
 	for _, v := range *fa {
 		if v.Name_.String() == f.Name_.String() { //&& v.Type.Equals(f.Type) {
-			loc := f.Name_.Loc
-			*unresolved = append(*unresolved, fmt.Errorf(`Duplicate input value name "%s" at line: %d, column: %d`, f.Name_, loc.Line, loc.Column))
+			*unresolved = append(*unresolved, Diagf(CodeDuplicate, f.Name_.Loc, `Duplicate input value name "%s"`, f.Name_).WithRelated("first defined here", v.Name_.Loc))
+			return
 		}
 	}
 	*fa = append(*fa, f)
@@ -802,7 +1005,7 @@ func (fa InputValueDefs) CheckDirectiveRef(dirName NameValue_, err *[]error) {
 func (fa InputValueDefs) CheckIsInputType(err *[]error) {
 	for _, p := range fa {
 		if !IsInputType(p.Type) {
-			*err = append(*err, fmt.Errorf(`Field "%s" of input type "%s", must be an input type %s`, p.Name_, p.Type.Name, p.Type.Name_.AtPosition()))
+			*err = append(*err, Diagf(CodeType, p.Type.Name_.Loc, `Field "%s" of input type "%s", must be an input type`, p.Name_, p.Type.Name))
 		}
 		//	_ := p.DefaultVal.isType() // e.g. scalar, int | List
 	}
@@ -820,6 +1023,8 @@ func (fa InputValueDefs) CheckInputValueType(err *[]error) {
 // InputValueDefinition
 //		Description-opt  Name : Type  =  DefaultValue-opt   Directives-opt
 type InputValueDef struct {
+	Comments_
+	Span_
 	Desc string
 	Name_
 	Type       *GQLtype
@@ -827,6 +1032,10 @@ type InputValueDef struct {
 	Directives_
 }
 
+func (fa *InputValueDef) Clone() *InputValueDef {
+	return &InputValueDef{Desc: fa.Desc, Comments_: fa.Comments_.Clone(), Span_: fa.Span_, Name_: fa.Name_.Clone(), Type: fa.Type.Clone(), DefaultVal: fa.DefaultVal.Clone(), Directives_: fa.Directives_.Clone()}
+}
+
 func (fa *InputValueDef) SolicitAbstractTypes(unresolved UnresolvedMap) { //TODO - check this..should it use unresolvedMap?
 	if fa.Type == nil {
 		err := fmt.Errorf("Severe Error - not expected: InputValueDef.Type is not assigned for [%s]", fa.Name_.String())
@@ -846,7 +1055,6 @@ func (fa *InputValueDef) CheckDirectiveLocation(err *[]error) {
 func (fa *InputValueDef) CheckDirectiveRef(dir NameValue_, err *[]error) {
 
 	refCheck := func(dirName NameValue_, x GQLTypeProvider) {
-		fmt.Println("inputValueDef refcheck for ", dirName, x.TypeName())
 		x.CheckDirectiveRef(dirName, err)
 	}
 
@@ -866,9 +1074,15 @@ func (fa *InputValueDef) AssignType(t *GQLtype) {
 	fa.Type = t
 }
 
+func (fa *InputValueDef) AssignDesc(desc string) {
+	fa.Desc = desc
+}
+
 func (fa *InputValueDef) String() string {
 	var s strings.Builder
 	s.WriteString(" ")
+	fa.writeLeading(&s)
+	writeDesc(&s, fa.Desc)
 	s.WriteString(fa.Name_.String())
 	s.WriteString(" : " + fa.Type.String() + " ")
 	if fa.DefaultVal != nil {
@@ -877,6 +1091,7 @@ func (fa *InputValueDef) String() string {
 	}
 	s.WriteString(" ")
 	s.WriteString(fa.Directives_.String())
+	fa.writeTrailing(&s)
 	return s.String()
 }
 
@@ -893,6 +1108,8 @@ func (a *InputValueDef) CheckInputValueType(err *[]error) {
 //		EnumValuesDefinition
 //		{EnumValueDefinitionlist}
 type Enum_ struct {
+	Comments_
+	Span_
 	Desc string
 	Name_
 	Directives_
@@ -900,6 +1117,16 @@ type Enum_ struct {
 }
 
 func (e *Enum_) TypeSystemNode() {}
+func (e *Enum_) Clone() *Enum_ {
+	c := &Enum_{Desc: e.Desc, Comments_: e.Comments_.Clone(), Span_: e.Span_, Name_: e.Name_.Clone(), Directives_: e.Directives_.Clone()}
+	if e.Values != nil {
+		c.Values = make([]*EnumValue_, len(e.Values))
+		for i, v := range e.Values {
+			c.Values[i] = v.Clone()
+		}
+	}
+	return c
+}
 func (e *Enum_) SolicitAbstractTypes(unresolved UnresolvedMap) {
 	e.Directives_.SolicitAbstractTypes(unresolved)
 	for _, v := range e.Values {
@@ -915,19 +1142,28 @@ func (e *Enum_) TypeName() NameValue_ {
 	return e.Name
 }
 
+func (e *Enum_) AssignDesc(desc string) {
+	e.Desc = desc
+}
+
 func (e *Enum_) String() string {
 	var s strings.Builder
+	e.writeLeading(&s)
+	writeDesc(&s, e.Desc)
 	s.WriteString("enum " + e.Name_.String())
 	s.WriteString(e.Directives_.String())
+	var body strings.Builder
 	for i, v := range e.Values {
 		if i == 0 {
-			s.WriteString("{\n")
+			body.WriteString("{\n")
 		}
-		s.WriteString(v.String() + "\n")
+		body.WriteString(v.String() + "\n")
 		if i == len(e.Values)-1 {
-			s.WriteString("}\n")
+			body.WriteString("}")
 		}
 	}
+	e.writeBody(&s, body.String())
+	s.WriteString("\n")
 	return s.String()
 }
 
@@ -949,6 +1185,8 @@ func (e *Enum_) CheckInputValueType(err *[]error) {
 //	EnumValueDefinition
 //		Description-opt EnumValue Directives-const-opt
 type EnumValue_ struct {
+	Comments_
+	Span_
 	Desc string
 	Name_
 	Directives_
@@ -962,6 +1200,9 @@ func (e *EnumValue_) IsType() TypeFlag_ {
 	return ENUMVALUE
 }
 func (e *EnumValue_) TypeSystemNode() {}
+func (e *EnumValue_) Clone() *EnumValue_ {
+	return &EnumValue_{Desc: e.Desc, Comments_: e.Comments_.Clone(), Span_: e.Span_, Name_: e.Name_.Clone(), Directives_: e.Directives_.Clone(), hostValue: cloneValue(e.hostValue)}
+}
 func (e *EnumValue_) SolicitAbstractTypes(unresolved UnresolvedMap) {
 	for _, v := range e.Directives {
 		unresolved[v.Name_] = nil
@@ -981,12 +1222,19 @@ func (e *EnumValue_) AssignName(s string, l *Loc_, unresolved *[]error) {
 func (e *EnumValue_) TypeName() NameValue_ {
 	return e.Name
 }
+
+func (e *EnumValue_) AssignDesc(desc string) {
+	e.Desc = desc
+}
 func (e *EnumValue_) String() string {
 	var s strings.Builder
+	e.writeLeading(&s)
+	writeDesc(&s, e.Desc)
 	s.WriteString(e.Name_.String())
 	if e.Directives != nil {
 		s.WriteString(" " + e.Directives_.String())
 	}
+	e.writeTrailing(&s)
 	return s.String()
 }
 
@@ -1006,14 +1254,14 @@ func (e *EnumValue_) CheckEnumValue(a *GQLtype, err *[]error) {
 				}
 			}
 			if !found {
-				*err = append(*err, fmt.Errorf(` "%s" is not a member of Enum type %s %s`, e.Name_, a.Name, e.Name_.AtPosition()))
+				*err = append(*err, Diagf(CodeValue, e.Name_.Loc, ` "%s" is not a member of Enum type %s`, e.Name_, a.Name))
 			}
 		default:
-			*err = append(*err, fmt.Errorf(`Type "%s" is not an ENUM but argument value "%s" is an ENUM value `, a.Name, e.Name_, e.Name_.AtPosition()))
+			*err = append(*err, Diagf(CodeValue, e.Name_.Loc, `Type "%s" is not an ENUM but argument value "%s" is an ENUM value`, a.Name, e.Name_))
 		}
 
 	} else {
-		*err = append(*err, fmt.Errorf(`Enum type "%s" is not found in cache %s`, a.Name, e.Name_.AtPosition()))
+		*err = append(*err, Diagf(CodeType, e.Name_.Loc, `Enum type "%s" is not found in cache`, a.Name))
 	}
 }
 
@@ -1032,18 +1280,27 @@ type Schema struct {
 // ======================  Interface =========================
 
 // InterfaceTypeDefinition
-//		Description-opt interface Name Directives-opt FieldsDefinition-opt
+//		Description-opt interface Name ImplementsInterfaces-opt Directives-opt FieldsDefinition-opt
 type Interface_ struct {
+	Comments_
+	Span_
 	Desc string
 	Name_
+	Implements NameS
 	Directives_
 	FieldSet
 }
 
 func (i *Interface_) TypeSystemNode() {}
+func (i *Interface_) Clone() *Interface_ {
+	return &Interface_{Desc: i.Desc, Comments_: i.Comments_.Clone(), Span_: i.Span_, Name_: i.Name_.Clone(), Implements: i.Implements.Clone(), Directives_: i.Directives_.Clone(), FieldSet: i.FieldSet.Clone()}
+}
 func (i *Interface_) SolicitAbstractTypes(unresolved UnresolvedMap) {
 	i.Directives_.SolicitAbstractTypes(unresolved)
 	i.FieldSet.SolicitAbstractTypes(unresolved)
+	for _, v := range i.Implements {
+		unresolved[v] = nil
+	}
 }
 
 func (i *Interface_) Type() string {
@@ -1058,6 +1315,7 @@ func (i *Interface_) GetSelectionSet() FieldSet {
 
 func (i *Interface_) CheckDirectiveLocation(err *[]error) {
 	i.checkDirectiveLocation_(INTERFACE_DL, err)
+	i.FieldSet.CheckDirectiveLocation(err)
 }
 
 //func (i *Interface_) AssignUnresolvedTypes(ast TypeRepo) error {}
@@ -1065,38 +1323,69 @@ func (i *Interface_) AssignName(input string, loc *Loc_, unresolved *[]error) {
 	i.Name_.AssignName(input, loc, unresolved)
 }
 
+func (i *Interface_) AssignDesc(desc string) {
+	i.Desc = desc
+}
+
 func (i *Interface_) String() string {
 	var s strings.Builder
+	i.writeLeading(&s)
+	writeDesc(&s, i.Desc)
 	s.WriteString("interface ")
 	s.WriteString(i.Name_.String())
+	s.WriteString(i.Implements.String())
 	s.WriteString(" " + i.Directives_.String())
-	s.WriteString(" " + i.FieldSet.String())
+	s.WriteString(" ")
+	i.writeBody(&s, i.FieldSet.String())
 	return s.String()
 }
 
+// Conform reports whether obj is an object whose fields validly implement the fields of the interface.
 func (i *Interface_) Conform(obj GQLTypeProvider) bool {
 	obj_, ok := obj.(*Object_)
 	if !ok {
 		return false
 	}
-	if len(i.FieldSet) > len(obj_.FieldSet) {
-		return false
+	var errs []error
+	checkFields("Type", obj_.Name_, obj_.FieldSet, i, &errs)
+	return len(errs) == 0
+}
+func (i *Interface_) AppendImplements(nm Name_) error {
+	if nm.Name.Equals(i.Name) {
+		return Diagf(CodeImplements, nm.Loc, `Interface "%s" cannot implement itself`, nm)
 	}
-	for _, fld := range i.FieldSet {
-		var found bool
-		for _, objFld := range obj_.FieldSet {
-			if fld.Name.Equals(objFld.Name) {
-				found = true
-				break
-			}
-			if !found {
-				return false
-			}
+	return i.Implements.appendImplements(nm)
+}
+
+// CheckImplements validates the interfaces implemented by the interface, including the detection of any cycle
+// formed through the interfaces it implements e.g. A implements B, B implements C, C implements A.
+func (i *Interface_) CheckImplements(err *[]error) {
+	if path := i.implementsCycle(i.Name, map[NameValue_]bool{}); len(path) > 0 {
+		*err = append(*err, Diagf(CodeImplements, i.Name_.Loc, `Interface "%s" cannot implement itself, cycle: %s`, i.Name_, strings.Join(path, " -> ")))
+		return
+	}
+	checkImplements("Interface", i.Name_, i.Implements, i.FieldSet, err)
+}
 
+// implementsCycle walks the implemented interfaces depth first returning the path back to root, if one exists.
+func (i *Interface_) implementsCycle(root NameValue_, visited map[NameValue_]bool) []string {
+	visited[i.Name] = true
+	for _, v := range i.Implements {
+		if v.Name.Equals(root) {
+			return []string{i.Name.String(), root.String()}
+		}
+		if visited[v.Name] {
+			continue
+		}
+		if itf, ok := TyCache[v.Name.String()].(*Interface_); ok {
+			if path := itf.implementsCycle(root, visited); len(path) > 0 {
+				return append([]string{i.Name.String()}, path...)
+			}
 		}
 	}
-	return true
+	return nil
 }
+
 func (i *Interface_) CheckFieldMembers(err *[]error) {
 	// Fields on a GraphQL interface have the same rules as fields on a GraphQL object;
 	// their type can be Scalar, Object, Enum, Interface, or Union, or any wrapping type whose base type is one of those five.
@@ -1104,7 +1393,7 @@ func (i *Interface_) CheckFieldMembers(err *[]error) {
 		switch v.Type.isType() {
 		case OBJECT, ENUM, INTERFACE, UNION, FLOAT, INT, BOOLEAN, ID, SCALAR, STRING:
 		default:
-			*err = append(*err, fmt.Errorf(`Member %q of interface %q is not an appropriate type. Must be a scalar, object, enum, interface or union, %s`, v.Name, i.TypeName(), v.Name_.AtPosition()))
+			*err = append(*err, Diagf(CodeType, v.Name_.Loc, `Member %q of interface %q is not an appropriate type. Must be a scalar, object, enum, interface or union`, v.Name, i.TypeName()))
 		}
 	}
 }
@@ -1119,6 +1408,8 @@ func (i *Interface_) CheckInputValueType(err *[]error) {
 // InterfaceTypeDefinition
 //		Description-opt interface Name Directives-opt FieldsDefinition-opt
 type Union_ struct {
+	Comments_
+	Span_
 	Desc string
 	Name_
 	Directives_
@@ -1126,6 +1417,9 @@ type Union_ struct {
 }
 
 func (u *Union_) TypeSystemNode() {}
+func (u *Union_) Clone() *Union_ {
+	return &Union_{Desc: u.Desc, Comments_: u.Comments_.Clone(), Span_: u.Span_, Name_: u.Name_.Clone(), Directives_: u.Directives_.Clone(), NameS: u.NameS.Clone()}
+}
 func (u *Union_) SolicitAbstractTypes(unresolved UnresolvedMap) { // TODO check this is being executed
 	u.Directives_.SolicitAbstractTypes(unresolved)
 	for _, v := range u.NameS {
@@ -1140,6 +1434,10 @@ func (u *Union_) TypeName() NameValue_ {
 	return u.Name
 }
 
+func (u *Union_) AssignDesc(desc string) {
+	u.Desc = desc
+}
+
 func (u *Union_) CheckDirectiveLocation(err *[]error) {
 	u.checkDirectiveLocation_(UNION_DL, err)
 }
@@ -1159,7 +1457,10 @@ func (u *Union_) CheckDirectiveLocation(err *[]error) {
 
 func (u *Union_) String() string {
 	var s strings.Builder
-	s.WriteString("\nunion ")
+	s.WriteString("\n")
+	u.writeLeading(&s)
+	writeDesc(&s, u.Desc)
+	s.WriteString("union ")
 	s.WriteString(u.Name_.String())
 	s.WriteString(" " + u.Directives_.String())
 	for i, v := range u.NameS {
@@ -1171,6 +1472,7 @@ func (u *Union_) String() string {
 		s.WriteString(v.String())
 	}
 
+	u.writeTrailing(&s)
 	return s.String()
 }
 
@@ -1183,6 +1485,8 @@ func (u *Union_) String() string {
 // InputObjectTypeDefinition
 //		Description-opt	input	Name	DirectivesConst-opt	InputFieldsDefinition-opt
 type Input_ struct {
+	Comments_
+	Span_
 	Desc string
 	Name_
 	Directives_
@@ -1190,6 +1494,9 @@ type Input_ struct {
 }
 
 func (e *Input_) TypeSystemNode() {}
+func (e *Input_) Clone() *Input_ {
+	return &Input_{Desc: e.Desc, Comments_: e.Comments_.Clone(), Span_: e.Span_, Name_: e.Name_.Clone(), Directives_: e.Directives_.Clone(), InputValueDefs: e.InputValueDefs.Clone()}
+}
 
 //func (e *Input_) ValueNode()      {}// commented out 19/3/2020
 func (e *Input_) SolicitAbstractTypes(unresolved UnresolvedMap) { // TODO check this is being executed
@@ -1210,6 +1517,10 @@ func (i *Input_) TypeName() NameValue_ {
 	return i.Name
 }
 
+func (i *Input_) AssignDesc(desc string) {
+	i.Desc = desc
+}
+
 func (i *Input_) CheckDirectiveLocation(err *[]error) {
 	i.Directives_.checkDirectiveLocation_(INPUT_OBJECT_DL, err)
 	for _, v := range i.InputValueDefs {
@@ -1225,13 +1536,16 @@ func (i *Input_) CheckDirectiveRef(dir NameValue_, err *[]error) {
 func (u *Input_) String() string {
 	var encl [2]token.TokenType = [2]token.TokenType{token.LBRACE, token.RBRACE}
 	var s strings.Builder
-	s.WriteString("\ninput ")
+	s.WriteString("\n")
+	u.writeLeading(&s)
+	writeDesc(&s, u.Desc)
+	s.WriteString("input ")
 	s.WriteString(" ")
 	s.WriteString(u.Name.String())
 	s.WriteString(" ")
 	s.WriteString(" " + u.Directives_.String())
 	s.WriteString(" ")
-	s.WriteString(u.InputValueDefs.String(encl))
+	u.writeBody(&s, u.InputValueDefs.String(encl))
 	return s.String()
 }
 
@@ -1251,17 +1565,22 @@ type ScalarProvider interface {
 // ScalarTypeDefinition:
 //		Description-opt	input	Name	DirectivesConst-opt	InputFieldsDefinition-opt
 type Scalar_ struct {
+	Comments_
+	Span_
 	Desc string
 	Name string // no need to hold Location as its stored in InputValue, parent of this object
 	Loc  *Loc_
 	Directives_
-	Data string
-	//
-	// scalar data validator types (may not be necessary)
-	//
-	TimeV   time.Time // any date-time
-	NumberV float64   // any number
-	IntV    int64     // any int
+	Data  string      // literal of a coerced input value, as printed
+	Value interface{} // internal value of a coerced input value, see RegisterScalar
+}
+
+func (e *Scalar_) Clone() *Scalar_ {
+	c := *e
+	c.Comments_ = e.Comments_.Clone()
+	c.Loc = e.Loc.Clone()
+	c.Directives_ = e.Directives_.Clone()
+	return &c
 }
 
 func (e *Scalar_) TypeSystemNode() {}
@@ -1290,11 +1609,19 @@ func (e *Scalar_) AssignName(s string, loc *Loc_, errS *[]error) {
 	e.Loc = loc
 }
 
+func (e *Scalar_) AssignDesc(desc string) {
+	e.Desc = desc
+}
+
 func (u *Scalar_) String() string {
 	var s strings.Builder
-	s.WriteString("\nscalar ")
+	s.WriteString("\n")
+	u.writeLeading(&s)
+	writeDesc(&s, u.Desc)
+	s.WriteString("scalar ")
 	s.WriteString(u.Name)
 	s.WriteString(" " + u.Directives_.String())
+	u.writeTrailing(&s)
 	return s.String()
 }
 
@@ -1309,41 +1636,34 @@ func (u *Scalar_) String() string {
 // 	}
 // }
 
+// Coerce converts the input value to a value of the scalar using the functions registered for the scalar, see RegisterScalar.
+// The input value of a scalar that is not registered is accepted as is.
 func (s *Scalar_) Coerce(input InputValueProvider) (InputValueProvider, error) {
-
-	fmt.Printf(" %#v, s.Name  %s = \n", s, s.Name)
-	switch s.Name {
-
-	case "Time":
-		// coerce input to scalar Time
-		switch in := input.(type) {
-		case String_:
-			// try to convert string input value to a scalar (time) iv
-			const longForm = "Jan 2, 2006 at 3:04pm (MST)"
-			if t, err := time.Parse(longForm, in.String()); err != nil {
-				const shortForm = "2006-Jan-02"
-				if t, err = time.Parse(shortForm, in.String()); err != nil {
-					if t, err = time.Parse(time.RFC3339, in.String()); err != nil {
-						return nil, fmt.Errorf("Error in parsing of Time value ")
-					}
-				}
-			} else {
-				// convert input value from string to time
-
-				b := &Scalar_{Name: "Time", Data: in.String(), TimeV: t}
-				fmt.Printf("*** Cource input from String_ b %#v, s.Name  %s = \n", b, b.Name)
-				return b, nil
-			}
+	if x, ok := input.(*Scalar_); ok && x.Name == s.Name {
+		// already coerced e.g. a default value validated again once the type is extended
+		return input, nil
+	}
+	f, ok := LookupScalar(s.Name)
+	if !ok {
+		return &Scalar_{Name: s.Name, Data: (&InputValue_{InputValueProvider: input}).String(), Value: input}, nil
+	}
+	v, err := f.Parse(input)
+	if err != nil {
+		return nil, err
+	}
+	if f.Validate != nil {
+		if err := f.Validate(v); err != nil {
+			return nil, err
 		}
-		// if t_, ok := s.(RawString_); ok {
-		// 	// convert string to time
-		// 	return tc_, nil
-		// }
-		return nil, fmt.Errorf("%s must be a string to be coerced to Time", s.Name)
-
-	default:
-		return nil, fmt.Errorf("%s is not a Scalar ", s.Name)
 	}
+	lit := input
+	if f.Serialize != nil {
+		if lit, err = f.Serialize(v); err != nil {
+			return nil, err
+		}
+	}
+	debug("coerced value", logger.Type(s.Name), logger.Any("value", v))
+	return &Scalar_{Name: s.Name, Data: (&InputValue_{InputValueProvider: lit}).String(), Value: v}, nil
 }
 
 // ======================  Directive_ =========================
@@ -1351,13 +1671,23 @@ func (s *Scalar_) Coerce(input InputValueProvider) (InputValueProvider, error) {
 //		Description-opt	input	Name	DirectivesConst-opt	InputFieldsDefinition-opt
 
 type Directive_ struct {
+	Comments_
+	Span_
 	Desc         string
 	Name_                       // no need to hold Location as its stored in InputValue, parent of this object
 	ArgumentDefs InputValueDefs //TODO consider making InputValueDefs an embedded type ie. an anonymous field
+	Repeatable   bool           // directive may be applied more than once at the same location
 	Location     []DirectiveLoc
 }
 
 func (d *Directive_) TypeSystemNode() {}
+func (d *Directive_) Clone() *Directive_ {
+	c := &Directive_{Desc: d.Desc, Comments_: d.Comments_.Clone(), Span_: d.Span_, Name_: d.Name_.Clone(), ArgumentDefs: d.ArgumentDefs.Clone(), Repeatable: d.Repeatable}
+	if d.Location != nil {
+		c.Location = append([]DirectiveLoc(nil), d.Location...)
+	}
+	return c
+}
 
 //
 func (d *Directive_) Type() string {
@@ -1389,14 +1719,24 @@ func (d *Directive_) AssignName(input string, loc *Loc_, err *[]error) {
 	d.Name_.AssignName(input, loc, err)
 }
 
+func (d *Directive_) AssignDesc(desc string) {
+	d.Desc = desc
+}
+
 func (d *Directive_) String() string {
 	var (
 		s    strings.Builder
 		encl [2]token.TokenType = [2]token.TokenType{token.LPAREN, token.RPAREN}
 	)
-	s.WriteString("\ndirective ")
+	s.WriteString("\n")
+	d.writeLeading(&s)
+	writeDesc(&s, d.Desc)
+	s.WriteString("directive ")
 	s.WriteString(d.Name.String())
 	s.WriteString(d.ArgumentDefs.String(encl))
+	if d.Repeatable {
+		s.WriteString(" repeatable")
+	}
 	if len(d.Location) > 0 {
 
 		s.WriteString(" on ")
@@ -1409,13 +1749,14 @@ func (d *Directive_) String() string {
 			}
 		}
 	}
+	d.writeTrailing(&s)
 	return s.String()
 }
 
 func (d *Directive_) CheckIsInputType(err *[]error) {
 	for _, p := range d.ArgumentDefs {
 		if !IsInputType(p.Type) {
-			*err = append(*err, fmt.Errorf(`Argument "%s" type "%s", is not an input type %s`, p.Name_, p.Type.Name, p.Type.Name_.AtPosition()))
+			*err = append(*err, Diagf(CodeType, p.Type.Name_.Loc, `Argument "%s" type "%s", is not an input type`, p.Name_, p.Type.Name))
 		}
 		//	_ := p.DefaultVal.isType() // e.g. scalar, int | List
 	}
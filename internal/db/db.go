@@ -13,6 +13,7 @@ import (
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 	"github.com/rosshpayne/graph-sdl/ast"
+	"github.com/rosshpayne/graph-sdl/logger"
 )
 
 const (
@@ -24,16 +25,147 @@ var (
 	defaultDoc string
 
 	db *dynamodb.DynamoDB
+
+	logr logger.Logger = logger.Nop
 )
 
+// SetLogger sets the logger of the package. A nil logger silences logging.
+func SetLogger(l logger.Logger) {
+	if l == nil {
+		l = logger.Nop
+	}
+	logr = l
+}
+
 type TypeRow struct {
 	PKey  string
 	SortK string
 	Stmt  string
-	Type  string //this maps to ast.Type.Base - reqired for ENUM types but maybe useful for others
-	I     string // Insert time
-	U     string // Update time
-	D     string // Delete time
+	Type  string                   //this maps to ast.Type.Base - reqired for ENUM types but maybe useful for others
+	I     string                   // Insert time
+	U     string                   // Update time
+	D     string                   // Delete time
+	R     int                      // Revision, incremented each time the statement is written. Zero for rows written before revisions.
+	Dp    map[string]DeprecatedRow // deprecated members by path, see ast.Members
+}
+
+// DeprecatedRow records when a member of a type was deprecated.
+type DeprecatedRow struct {
+	R int    // revision in which the member was deprecated
+	T string // time that revision was written
+}
+
+// Since returns when the member was deprecated.
+func (d DeprecatedRow) Since() time.Time {
+	return parseTime(d.T)
+}
+
+// timeLayout is the layout of the times of a row. Rows written before revisions use legacyTimeLayout, which has no year.
+const (
+	timeLayout       = time.RFC3339
+	legacyTimeLayout = "Mon Jan 2 15:04:05"
+)
+
+// Revision returns the revision of the row. A row written before revisions is the first revision.
+func (r *TypeRow) Revision() int {
+	if r.R == 0 {
+		return 1
+	}
+	return r.R
+}
+
+// Inserted returns the time the statement was first written.
+func (r *TypeRow) Inserted() time.Time {
+	return parseTime(r.I)
+}
+
+// Updated returns the time the statement was last rewritten, or the zero time if it has not been.
+func (r *TypeRow) Updated() time.Time {
+	return parseTime(r.U)
+}
+
+func parseTime(s string) time.Time {
+	if t, err := time.ParseInLocation(timeLayout, s, location); err == nil {
+		return t
+	}
+	t, _ := time.ParseInLocation(legacyTimeLayout, s, location)
+	return t
+}
+
+// NewRow returns the row that stores the statement ast_ under pkey in the current document, as the next revision
+// of the stored row, or as the first revision when stored is nil.
+func NewRow(pkey string, ast_ ast.GQLTypeProvider, stored *TypeRow) *TypeRow {
+	now := time.Now().In(location).Format(timeLayout)
+	row := &TypeRow{PKey: pkey, SortK: document, Stmt: ast_.String(), I: now, R: 1}
+	switch ast_.(type) {
+	case *ast.Directive_:
+		row.Type = "D"
+	case *ast.Object_:
+		row.Type = "O"
+	default:
+		row.Type = ast.IsGLType(ast_)
+	}
+	if stored != nil {
+		row.I, row.U, row.R = stored.I, now, stored.Revision()+1
+	}
+	// a member deprecated by an earlier revision keeps the revision it was deprecated in
+	for _, d := range ast.Deprecations(ast_) {
+		if row.Dp == nil {
+			row.Dp = make(map[string]DeprecatedRow)
+		}
+		if dp, ok := stored.deprecated(d.Path); ok {
+			row.Dp[d.Path] = dp
+		} else {
+			row.Dp[d.Path] = DeprecatedRow{R: row.R, T: now}
+		}
+	}
+	return row
+}
+
+// RecordDeprecations adds the deprecated members of ast_ the row does not record, as deprecated in the revision of
+// the row. A row written before deprecations were recorded holds none. Reports whether any were added.
+func (r *TypeRow) RecordDeprecations(ast_ ast.GQLTypeProvider) bool {
+	var added bool
+	for _, d := range ast.Deprecations(ast_) {
+		if _, ok := r.Dp[d.Path]; ok {
+			continue
+		}
+		if r.Dp == nil {
+			r.Dp = make(map[string]DeprecatedRow)
+		}
+		r.Dp[d.Path] = r.deprecatedRow()
+		added = true
+	}
+	return added
+}
+
+// Deprecations returns the deprecated members recorded by the row. A row written before deprecations were recorded holds
+// none, so the members deprecated in its statement, stmt, are taken as deprecated in the revision of the row.
+func (r *TypeRow) Deprecations(stmt ast.GQLTypeProvider) map[string]DeprecatedRow {
+	if len(r.Dp) > 0 {
+		return r.Dp
+	}
+	dp := make(map[string]DeprecatedRow)
+	for _, d := range ast.Deprecations(stmt) {
+		dp[d.Path] = r.deprecatedRow()
+	}
+	return dp
+}
+
+// deprecatedRow records a member as deprecated when the revision of the row was written.
+func (r *TypeRow) deprecatedRow() DeprecatedRow {
+	if len(r.U) > 0 {
+		return DeprecatedRow{R: r.Revision(), T: r.U}
+	}
+	return DeprecatedRow{R: r.Revision(), T: r.I}
+}
+
+func (r *TypeRow) deprecated(path string) (DeprecatedRow, bool) {
+	if r == nil {
+		return DeprecatedRow{}, false
+	}
+	dp, ok := r.Dp[path]
+	return dp, ok
 }
 
 type PkRow struct {
@@ -80,9 +212,10 @@ func buildKey(input string) string {
 	return s.String()
 }
 
-func Persist(input string, ast_ ast.GQLTypeProvider) error {
+// Persist writes the row of the statement ast_, see NewRow.
+func Persist(row *TypeRow, ast_ ast.GQLTypeProvider) error {
 	// save GraphQL statement to Dynamodb
-	if err := dbPersist(input, ast_); err != nil {
+	if err := dbPersist(row, ast_); err != nil {
 		return err
 	}
 	return nil
@@ -101,12 +234,11 @@ func Persist(input string, ast_ ast.GQLTypeProvider) error {
 
 // }
 
-func dbPersist(pkey string, ast_ ast.GQLTypeProvider) error {
+func dbPersist(row *TypeRow, ast_ ast.GQLTypeProvider) error {
 	//
 	// TODO: check to see if item already exists, and if type is different error otherwise give a warning.
 	//		 table design ensures uniqueness of type with a given name, however currently it will overrite existing item
 	//
-	now := time.Now().In(location)
 	switch ast_.(type) {
 
 	case *ast.Directive_:
@@ -118,9 +250,12 @@ func dbPersist(pkey string, ast_ ast.GQLTypeProvider) error {
 			Type  string // Type of stmt - saves having to parse stmt to determine type
 			PKey_ string // Object belonging to interface
 			I     string // Insert time
+			U     string // Update time
+			R     int    // Revision
+			Dp    map[string]DeprecatedRow
 		}
 		//	typeDef := DirRow{PKey: pkey.String(), SortK: "D", Stmt: ast.String(), Dir: "D", Type: "D"}
-		typeDef := DirRow{PKey: pkey, SortK: document, Stmt: ast_.String(), Dir: "D", Type: "D", PKey_: ast_.String(), I: now.Format("Mon Jan 2 15:04:05")}
+		typeDef := DirRow{PKey: row.PKey, SortK: row.SortK, Stmt: row.Stmt, Dir: "D", Type: row.Type, PKey_: row.Stmt, I: row.I, U: row.U, R: row.R, Dp: row.Dp}
 		av, err := dynamodbattribute.MarshalMap(typeDef)
 		if err != nil {
 			return fmt.Errorf("%s: %s", "Error: failed to marshal type definition ", err.Error())
@@ -136,8 +271,7 @@ func dbPersist(pkey string, ast_ ast.GQLTypeProvider) error {
 
 	case *ast.Object_:
 		//	typeDef := TypeRow{PKey: pkey.String(), SortK: "__", Stmt: ast.String(), Type: "O"}
-		typeDef := TypeRow{PKey: pkey, SortK: document, Stmt: ast_.String(), Type: "O", I: now.Format("Mon Jan 2 15:04:05")}
-		av, err := dynamodbattribute.MarshalMap(typeDef)
+		av, err := dynamodbattribute.MarshalMap(row)
 		if err != nil {
 			return fmt.Errorf("%s: %s", "Error: failed to marshal type definition ", err.Error())
 		}
@@ -164,9 +298,7 @@ func dbPersist(pkey string, ast_ ast.GQLTypeProvider) error {
 
 	default:
 		//typeDef := TypeRow{PKey: pkey.String(), SortK: "__", Stmt: ast.String(), Type: isType(ast)}
-
-		typeDef := TypeRow{PKey: pkey, SortK: document, Stmt: ast_.String(), Type: ast.IsGLType(ast_), I: now.Format("Mon Jan 2 15:04:05")}
-		av, err := dynamodbattribute.MarshalMap(typeDef)
+		av, err := dynamodbattribute.MarshalMap(row)
 		if err != nil {
 			return fmt.Errorf("%s: %s", "Error: failed to marshal type definition ", err.Error())
 		}
@@ -232,7 +364,7 @@ func DeleteType(input string) error {
 		}
 		document = defaultDoc
 	}
-	fmt.Printf("delete %q from document %q \n", input, document)
+	logr.Log(logger.Info, "delete type", logger.Document(document), logger.Type(input))
 	typeDef := PkRow{PKey: input, SortK: document}
 	av, err := dynamodbattribute.MarshalMap(typeDef)
 	if err != nil {
@@ -300,18 +432,77 @@ func newDBFetchErr(pk string, sortk string, routine string, code string, err err
 	return &DBFetchErr{pk: pk, sortk: sortk, routine: routine, cat: cat, fatal: fatal}
 }
 
+// DocumentTypes returns the names of the statements stored in the current document.
+func DocumentTypes() ([]string, error) {
+	rows, err := DocumentStatements()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(rows))
+	for i, r := range rows {
+		names[i] = r.PKey
+	}
+	return names, nil
+}
+
+// DocumentStatements returns the statements stored in the current document in a single query of the SortK-index,
+// which projects the statement. Only the PKey, SortK and Stmt of each row are populated.
+func DocumentStatements() ([]TypeRow, error) {
+	if len(document) == 0 {
+		document = defaultDoc
+	}
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(TableName),
+		IndexName:              aws.String("SortK-index"),
+		KeyConditionExpression: aws.String("SortK = :doc"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":doc": {S: aws.String(document)},
+		},
+	}
+	var rows []TypeRow
+	for {
+		result, err := db.Query(input)
+		if err != nil {
+			var code string
+			if aerr, ok := err.(awserr.Error); ok {
+				code = aerr.Code()
+			}
+			return nil, newDBFetchErr("", document, "Query", code, err, SystemErr, true)
+		}
+		var page []TypeRow
+		if err := dynamodbattribute.UnmarshalListOfMaps(result.Items, &page); err != nil {
+			return nil, newDBFetchErr("", document, "Query", "", err, UnmarshalingErr, true)
+		}
+		rows = append(rows, page...)
+		if len(result.LastEvaluatedKey) == 0 {
+			return rows, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+// DBFetch returns the statement of the name stored in the current document.
 func DBFetch(name string) (string, error) {
+	rec, err := DBFetchRow(name)
+	if err != nil {
+		return "", err
+	}
+	return rec.Stmt, nil
+}
+
+// DBFetchRow returns the row of the statement of the name stored in the current document.
+func DBFetchRow(name string) (*TypeRow, error) {
 	//
 	// query on recipe name to get RecipeId and  book name
 	//
 	///var sortK string
-	fmt.Printf("DB Fetch name: [%s]\n", name)
 	if len(document) == 0 {
 		document = defaultDoc
 	}
+	logr.Log(logger.Debug, "fetch type", logger.Document(document), logger.Type(name))
 
 	if len(name) == 0 {
-		return "", fmt.Errorf("No DB search value provided")
+		return nil, fmt.Errorf("No DB search value provided")
 	}
 	// if name[0] == '@' {
 	// 	sortK = "D"
@@ -322,7 +513,7 @@ func DBFetch(name string) (string, error) {
 	pkey := PkRow{PKey: name, SortK: document}
 	av, err := dynamodbattribute.MarshalMap(&pkey)
 	if err != nil {
-		return "", newDBFetchErr(name, document, "MarshalMap", "", err, MarshalingErr, true)
+		return nil, newDBFetchErr(name, document, "MarshalMap", "", err, MarshalingErr, true)
 	}
 	input := &dynamodb.GetItemInput{
 		Key:       av,
@@ -338,18 +529,17 @@ func DBFetch(name string) (string, error) {
 		} else {
 			err_ = newDBFetchErr(name, document, "GetItem", "", err, SystemErr, true)
 		}
-		return "", err_
+		return nil, err_
 	}
-	fmt.Println("dbFetch: GetItem: Query ConsumedCapacity: \n", result.ConsumedCapacity)
+	logr.Log(logger.Debug, "fetched type", logger.Document(document), logger.Type(name), logger.Any("consumedCapacity", result.ConsumedCapacity))
 	//
 	if len(result.Item) == 0 {
-		return "", newDBFetchErr(name, document, "GetItem", "", nil, NoItemFoundErr, false)
+		return nil, newDBFetchErr(name, document, "GetItem", "", nil, NoItemFoundErr, false)
 	}
 	rec := &TypeRow{}
 	err = dynamodbattribute.UnmarshalMap(result.Item, rec)
 	if err != nil {
-		return "", newDBFetchErr(name, document, "MarshalMap", "", err, UnmarshalingErr, true)
+		return nil, newDBFetchErr(name, document, "MarshalMap", "", err, UnmarshalingErr, true)
 	}
-	fmt.Printf("DBfetch result: [%s] \n", rec.Stmt)
-	return rec.Stmt, nil
+	return rec, nil
 }
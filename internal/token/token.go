@@ -89,8 +89,10 @@ const (
 
 // Line and column position in input string
 type Pos struct {
-	Line int
-	Col  int
+	Line   int
+	Col    int
+	Offset int    // byte offset from the start of the source
+	File   string // name of the source, empty for an unnamed input
 }
 
 // Token is exposed via token package so lexer can create new instanes of this type as required.
@@ -100,7 +102,9 @@ type Token struct {
 	IsScalarType bool
 	Literal      string // string value of token - rune, string, int, float, bool
 	Loc          Pos    // line and column position of start of the token in input string. Used in Parser to print location of errors in the SDL statements.
+	End          Pos    // position immediately after the last character of the token
 	Illegal      bool
+	Err          string // why an illegal token is malformed, if known
 }
 
 var keywords = map[string]struct {
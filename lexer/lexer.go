@@ -1,27 +1,43 @@
 package lexer
 
 import (
-	_ "fmt"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"unicode"
+	"unicode/utf16"
 	"unicode/utf8"
+	"unsafe"
 
 	"github.com/rosshpayne/graph-sdl/internal/token"
 )
 
 // Lexer parses an Input string (embedded in token pkg) and returns it as tokens - defined in token package.
+//
+// The lexer is byte oriented: ASCII, which makes up nearly all of a schema, is read a byte at a time and only other
+// characters are decoded as UTF-8. The literal of a name, number, punctuator or string without escape sequences is a slice
+// of the input rather than a copy, and tokens are written to a dual buffer in the lexer, so the common path allocates
+// nothing per token.
 type Lexer struct {
 	//	Eloc  token.Pos // Loc of illegal char
-	input string
-	cLoc  int    // Current ie. just read, Location (index) of rune in input string
-	rLoc  int    // next read Location (index) of rune in input string
-	ch    rune   // current rune under examination, added to token during lex processings
-	del   string // string delimeter
-	Line  int
-	Col   int // curren col Loc
-	err   error
+	sources []Source
+	si      int    // index of the source being read
+	file    string // name of the source being read
+	input   string
+	cLoc    int  // Current ie. just read, Location (index) of rune in input string
+	tLoc    int  // Location (index) of the first rune of the token being read
+	rLoc    int  // next read Location (index) of rune in input string
+	ch      rune // current rune under examination, added to token during lex processings
+	Line    int
+	Col     int // curren col Loc
+	err     error
 	//
 	buffer [2]token.Token // dual buffer to hold current and peek token
 	bi     int            // buffer index
+	//
+	comments bool // emit comment tokens rather than skip comments
 }
 
 func (l *Lexer) CLoc() int {
@@ -35,31 +51,160 @@ func (l *Lexer) Input() string {
 func (l *Lexer) Loc() (int, int) {
 	return l.Line, l.Col
 }
+
+// Source is a named input e.g. the contents of a file. The name is reported in the location of each token.
+type Source struct {
+	Name  string
+	Input string
+}
+
 func New(input string) *Lexer {
-	l := &Lexer{input: input, Line: 1}
-	l.readRune() // prime lexer struct
+	return NewSources(Source{Input: input})
+}
+
+// NewBytes returns a lexer that reads input without copying it. The literals of the tokens share memory with input,
+// so input must not be modified while the lexer, or any literal it has returned, is in use.
+func NewBytes(input []byte) *Lexer {
+	return NewSources(Source{Input: bytesToString(input)})
+}
+
+// bytesToString returns b as a string that shares memory with b.
+func bytesToString(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	return *(*string)(unsafe.Pointer(&b))
+}
+
+// NewSources returns a lexer that reads the sources in turn as a single document, so a type defined in one source
+// can be referenced from another. Line and column positions restart at each source.
+func NewSources(src ...Source) *Lexer {
+	if len(src) == 0 {
+		src = []Source{{}}
+	}
+	l := &Lexer{sources: src}
+	l.load(0)
 	return l
 }
 
+// NewReader returns a lexer that reads all of r. The name identifies r in token locations and may be empty.
+func NewReader(r io.Reader, name string) (*Lexer, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading %q: %w", name, err)
+	}
+	return NewSources(Source{Name: name, Input: bytesToString(b)}), nil
+}
+
+// NewFiles returns a lexer that reads the files matching the glob patterns e.g. "schema/*.graphql".
+// The files of each pattern are read in lexical order.
+func NewFiles(patterns ...string) (*Lexer, error) {
+	var src []Source
+	for _, pattern := range patterns {
+		files, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("Error in file pattern %q: %w", pattern, err)
+		}
+		if len(files) == 0 {
+			return nil, fmt.Errorf("No files match %q", pattern)
+		}
+		for _, f := range files {
+			b, err := os.ReadFile(f)
+			if err != nil {
+				return nil, fmt.Errorf("Error reading %q: %w", f, err)
+			}
+			src = append(src, Source{Name: f, Input: bytesToString(b)})
+		}
+	}
+	return NewSources(src...), nil
+}
+
+// KeepComments returns the lexer in comment mode, where each # comment is returned as a COMMENT token, whose literal
+// is the text after the #, rather than skipped as whitespace. The parser attaches the comments to the definitions they lead or trail.
+func (l *Lexer) KeepComments() *Lexer {
+	l.comments = true
+	return l
+}
+
+// load makes the i'th source the input of the lexer.
+func (l *Lexer) load(i int) {
+	l.si = i
+	l.file = l.sources[i].Name
+	l.input = l.sources[i].Input
+	l.cLoc, l.rLoc = 0, 0
+	l.Line, l.Col = 1, 0
+	l.readRune() // prime lexer struct
+}
+
+// File returns the name of the source being read.
+func (l *Lexer) File() string {
+	return l.file
+}
+
+// NextToken returns the next token, with its start and end positions in the source.
+// The token is held in the lexer's dual buffer, so it is overwritten by the second following call to NextToken,
+// except for a comment token, which the parser holds until the definition it belongs to is read.
 func (l *Lexer) NextToken() *token.Token {
+	tok := l.next()
+	end := l.cLoc // the lexer is paused on the rune after the token
+	if tok.Type == token.ILLEGAL {
+		end = l.rLoc // the illegal rune is not read over
+	}
+	l.span(tok, l.tLoc, end)
+	return tok
+}
+
+// span sets the start offset and end position of a token that occupies the input from offset start up to,
+// but excluding, offset end.
+func (l *Lexer) span(tok *token.Token, start, end int) {
+	if end > len(l.input) {
+		end = len(l.input)
+	}
+	if start > end {
+		start = end
+	}
+	src := l.input[start:end]
+	tok.Loc.Offset = start
+	tok.End = token.Pos{Line: tok.Loc.Line, Col: tok.Loc.Col + len(src), Offset: end, File: tok.Loc.File}
+	if tok.Type != token.RAWSTRING {
+		// only a block string spans lines
+		return
+	}
+	if n := strings.Count(src, "\n"); n > 0 {
+		tok.End.Line += n
+		tok.End.Col = len(src) - strings.LastIndex(src, "\n")
+	}
+}
+
+func (l *Lexer) next() *token.Token {
 	var tok *token.Token
 	//	fmt.Printf("NextToken: %c\n", l.ch)
 	l.skipWhitespace() // scan to next non-whitespace and return its value as a token
+	for l.ch == 0 && l.rLoc >= len(l.input) && l.si < len(l.sources)-1 {
+		// end of the current source, continue with the next
+		l.load(l.si + 1)
+		l.skipWhitespace()
+	}
+	l.tLoc = l.cLoc
 	switch l.ch {
 	case '\ufeff':
 		tok = l.newToken(token.BOM, l.ch)
 	case '#':
+		if l.comments {
+			return l.readComment()
+		}
 		l.readToEol()
-		return l.NextToken()
+		return l.next()
 	case '.': // ... expand sequence
+		start := token.Pos{Line: l.Line, Col: l.Col, File: l.file}
 		if l.peekRune() == '.' {
 			//ch := l.ch
 			l.readRune()
 			if l.peekRune() == '.' {
 				//ch := l.ch
 				l.readRune()
-				literal := token.EXPAND
-				tok = &token.Token{Type: token.EXPAND, Literal: literal}
+				tok = l.slot(start)
+				tok.Type, tok.Literal = token.EXPAND, token.EXPAND
 			} else {
 				tok = l.newToken(token.ILLEGAL, l.ch)
 			}
@@ -67,20 +212,7 @@ func (l *Lexer) NextToken() *token.Token {
 			tok = l.newToken(token.ILLEGAL, l.ch)
 		}
 	case '"':
-		if l.peekRune() == '"' {
-			//ch := l.ch
-			l.readRune()
-			if l.peekRune() == '"' {
-				l.readRune()
-				l.del = token.RAWSTRINGDEL
-				tok = l.readString()
-			} else {
-				tok = l.newToken(token.ILLEGAL, l.ch)
-			}
-		} else {
-			l.del = token.STRINGDEL
-			tok = l.readString()
-		}
+		return l.readString()
 	case '|':
 		tok = l.newToken(token.BAR, l.ch)
 	case '!':
@@ -111,7 +243,12 @@ func (l *Lexer) NextToken() *token.Token {
 	case '=':
 		tok = l.newToken(token.ASSIGN, l.ch)
 	case 0:
-		tok = l.newToken(token.EOF, l.ch)
+		if l.eof() {
+			// the end of the input follows the last rune read
+			tok = l.newToken(token.EOF, l.ch, token.Pos{Line: l.Line, Col: l.Col + 1, File: l.file})
+		} else {
+			tok = l.newToken(token.EOF, l.ch)
+		}
 	case '&':
 		tok = l.newToken(token.AND, l.ch)
 	default:
@@ -149,9 +286,15 @@ func (l *Lexer) readRune() {
 	if l.rLoc >= len(l.input) {
 		l.ch = 0 // EOF
 		l.cLoc++
+	} else if b := l.input[l.rLoc]; b < utf8.RuneSelf {
+		l.ch = rune(b)
+		l.cLoc = l.rLoc
+		l.rLoc++
+		if !(b == '\n' || b == '\r') {
+			l.Col++
+		}
 	} else {
 		var size int
-		// TODO: check token type. Only comment and string need rune reads all others simple ascii will suffice
 		l.ch, size = utf8.DecodeRuneInString(l.input[l.rLoc:])
 		l.cLoc = l.rLoc
 		l.rLoc += size
@@ -166,6 +309,8 @@ func (l *Lexer) readRune() {
 func (l *Lexer) peekRune() rune {
 	if l.rLoc >= len(l.input) {
 		return 0
+	} else if b := l.input[l.rLoc]; b < utf8.RuneSelf {
+		return rune(b)
 	} else {
 		rn, _ := utf8.DecodeRuneInString(l.input[l.rLoc:])
 		return rn
@@ -173,118 +318,371 @@ func (l *Lexer) peekRune() rune {
 }
 
 func (l *Lexer) readIdentifier() *token.Token {
-	start := token.Pos{l.Line, l.Col}
+	start := token.Pos{Line: l.Line, Col: l.Col, File: l.file}
 	Loc := l.cLoc
-	for unicode.IsLetter(l.ch) || l.ch == '_' || unicode.IsDigit(l.ch) {
+	for isNameRune(l.ch) {
 		l.readRune()
 	}
-	return &token.Token{Cat: token.NONVALUE, Type: token.STRING, Literal: l.input[Loc:l.cLoc], Loc: start}
+	tok := l.slot(start)
+	tok.Cat, tok.Type, tok.Literal = token.NONVALUE, token.STRING, l.input[Loc:l.cLoc]
+	return tok
+}
+
+func isNameRune(ch rune) bool {
+	if ch < utf8.RuneSelf {
+		return ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch == '_' || isDigit(ch)
+	}
+	return unicode.IsLetter(ch) || unicode.IsDigit(ch)
 }
 
+// readNumber reads an IntValue or FloatValue. A malformed number e.g. a leading zero, a missing fraction or exponent digit,
+// or a name character immediately after the number, is returned as an illegal token with Err describing the fault.
+// The rest of a malformed number is read over so lexing resumes at the following token.
 func (l *Lexer) readNumber() *token.Token {
-	var tokenT token.TokenType = token.INT
-	var illegalT bool
+	var (
+		tokenT token.TokenType = token.INT
+		fault  string
+	)
 	sLoc := l.cLoc
-	start := token.Pos{l.Line, l.Col}
+	start := token.Pos{Line: l.Line, Col: l.Col, File: l.file}
 	if l.ch == '-' {
-		//l.skipWhitespace()
 		l.readRune()
 	}
-	for unicode.IsDigit(l.ch) {
+	switch {
+	case l.ch == '0':
 		l.readRune()
+		if isDigit(l.ch) {
+			fault = "leading zero"
+		}
+	case isDigit(l.ch):
+		l.readDigits()
+	default:
+		fault = "expected a digit"
 	}
-	tokenT = token.INT
-	switch l.ch {
-	case '.':
+	if len(fault) == 0 && l.ch == '.' {
+		tokenT = token.FLOAT
+		l.readRune()
+		if !isDigit(l.ch) {
+			fault = "expected a digit after the decimal point"
+		}
+		l.readDigits()
+	}
+	if len(fault) == 0 && (l.ch == 'e' || l.ch == 'E') {
 		tokenT = token.FLOAT
 		l.readRune()
-		for unicode.IsDigit(l.ch) {
+		if l.ch == '-' || l.ch == '+' {
 			l.readRune()
 		}
-		if l.ch == 'e' || l.ch == 'E' {
+		if !isDigit(l.ch) {
+			fault = "expected a digit in the exponent"
+		}
+		l.readDigits()
+	}
+	if len(fault) == 0 && (l.ch == '.' || l.ch == '_' || unicode.IsLetter(l.ch)) {
+		fault = fmt.Sprintf("unexpected character %q after the number", l.ch)
+	}
+	if len(fault) > 0 {
+		for l.ch == '.' || l.ch == '_' || l.ch == '-' || l.ch == '+' || unicode.IsLetter(l.ch) || unicode.IsDigit(l.ch) {
 			l.readRune()
-			if l.ch == '-' || l.ch == '+' {
-				l.readRune()
+		}
+	}
+	eLoc := l.cLoc
+	if eLoc > len(l.input) {
+		eLoc = len(l.input)
+	}
+	tok := l.slot(start)
+	tok.Cat, tok.Type, tok.Literal = token.VALUE, tokenT, l.input[sLoc:eLoc]
+	if len(fault) > 0 {
+		tok.Illegal = true
+		tok.Err = fmt.Sprintf("Invalid number %q, %s", tok.Literal, fault)
+	}
+	return tok
+}
+
+func (l *Lexer) readDigits() {
+	for isDigit(l.ch) {
+		l.readRune()
+	}
+}
+
+func isDigit(ch rune) bool {
+	return ch >= '0' && ch <= '9'
+}
+
+// readString reads a string or block string. The literal of the token is the value of the string, as defined by the spec,
+// rather than its source text i.e. escape sequences are resolved and a block string has its common indentation and
+// leading and trailing blank lines removed. The current rune is left as the first rune after the closing quote.
+// The value of a string without escape sequences is sliced from the input. Only once an escape sequence is met
+// is the value built up.
+func (l *Lexer) readString() *token.Token {
+	start := token.Pos{Line: l.Line, Col: l.Col, File: l.file}
+	tok := l.slot(start)
+	tok.Cat, tok.Type = token.VALUE, token.STRING
+	if l.peekRune() == '"' {
+		l.readRune()
+		if l.peekRune() != '"' {
+			l.readRune() // read over closing "
+			return tok
+		}
+		l.readRune()
+		tok.Type = token.RAWSTRING
+		return l.readBlockString(tok)
+	}
+	var (
+		b     strings.Builder
+		built bool // the value is being built in b
+		sLoc  = l.cLoc
+	)
+	for {
+		l.readRune()
+		switch {
+		case l.ch == '"':
+			if built {
+				tok.Literal = b.String()
+			} else {
+				tok.Literal = l.input[sLoc+1 : l.cLoc]
 			}
-			for unicode.IsDigit(l.ch) {
-				l.readRune()
+			l.readRune() // read over closing "
+			return tok
+		case l.ch == '\n' || l.ch == '\r' || l.eof():
+			// unterminated string. The line terminator is left for skipWhitespace to count.
+			tok.Illegal = true
+			eLoc := l.cLoc
+			if eLoc > len(l.input) {
+				eLoc = len(l.input)
+			}
+			tok.Literal = l.input[sLoc:eLoc]
+			return tok
+		case l.ch == '\\':
+			if !built {
+				b.WriteString(l.input[sLoc+1 : l.cLoc])
+				built = true
+			}
+			if !l.readEscape(&b) {
+				tok.Illegal = true
+			}
+		case l.ch == utf8.RuneError && !built:
+			// an invalid byte is replaced by U+FFFD in the value
+			b.WriteString(l.input[sLoc+1 : l.cLoc])
+			built = true
+			b.WriteRune(l.ch)
+		default:
+			if built {
+				b.WriteRune(l.ch)
 			}
 		}
+	}
+}
 
-	case 'e', 'E':
-		tokenT = token.FLOAT
+// readEscape appends the character represented by the escape sequence that starts at the current rune.
+// It returns false for an invalid escape sequence, leaving the rune following the backslash unread.
+func (l *Lexer) readEscape(b *strings.Builder) bool {
+	switch l.peekRune() {
+	case '"', '\\', '/':
+		b.WriteRune(l.peekRune())
+	case 'b':
+		b.WriteByte('\b')
+	case 'f':
+		b.WriteByte('\f')
+	case 'n':
+		b.WriteByte('\n')
+	case 'r':
+		b.WriteByte('\r')
+	case 't':
+		b.WriteByte('\t')
+	case 'u':
 		l.readRune()
-		if l.ch == '-' || l.ch == '+' {
-			l.readRune()
+		r, ok := l.readUnicode()
+		if !ok {
+			return false
 		}
-		for unicode.IsDigit(l.ch) {
+		if utf16.IsSurrogate(r) {
+			// a character outside the Basic Multilingual Plane is written as a surrogate pair of escape sequences
+			if !strings.HasPrefix(l.input[l.rLoc:], `\u`) {
+				return false
+			}
 			l.readRune()
+			l.readRune()
+			r2, ok := l.readUnicode()
+			if !ok {
+				return false
+			}
+			if r = utf16.DecodeRune(r, r2); r == unicode.ReplacementChar {
+				return false
+			}
 		}
+		if !utf8.ValidRune(r) {
+			return false
+		}
+		b.WriteRune(r)
+		return true
+	default:
+		return false
+	}
+	l.readRune()
+	return true
+}
 
-	default: // all letters other than e E
-		if unicode.IsLetter(l.ch) {
-			//l.Eloc = token.Pos{l.Line, l.Col}
-			// token is now interpreted as an illegal IDENT
-			illegalT = true
-			tokenT = token.IDENT
-			for !(unicode.IsSpace(l.ch)) {
+// readUnicode reads the hex digits of a unicode escape sequence, either four digits e.g. \u00E9 or
+// braced e.g. \u{1F600}. The current rune is the "u". Digits are peeked, so an invalid sequence does not read
+// over the closing quote of the string.
+func (l *Lexer) readUnicode() (rune, bool) {
+	var r rune
+	if l.peekRune() == '{' {
+		l.readRune()
+		for n := 0; ; n++ {
+			if l.peekRune() == '}' {
 				l.readRune()
+				return r, n > 0
 			}
+			d, ok := hexValue(l.peekRune())
+			if !ok || r > unicode.MaxRune {
+				return 0, false
+			}
+			l.readRune()
+			r = r<<4 | d
 		}
 	}
-	last := l.cLoc // no digits read after + or -
-	if sLoc == l.cLoc {
-		tokenT = token.IDENT
-		illegalT = true
-		last = l.cLoc + 2 // include rune next to + or -
-		l.readRune()      // read over + -
+	for i := 0; i < 4; i++ {
+		d, ok := hexValue(l.peekRune())
+		if !ok {
+			return 0, false
+		}
+		l.readRune()
+		r = r<<4 | d
 	}
-	return &token.Token{Cat: token.VALUE, Type: tokenT, Literal: l.input[sLoc:last], Illegal: illegalT, Loc: start}
-
+	return r, true
 }
 
-func (l *Lexer) readString() *token.Token {
+func hexValue(ch rune) (rune, bool) {
+	switch {
+	case ch >= '0' && ch <= '9':
+		return ch - '0', true
+	case ch >= 'a' && ch <= 'f':
+		return ch - 'a' + 10, true
+	case ch >= 'A' && ch <= 'F':
+		return ch - 'A' + 10, true
+	}
+	return 0, false
+}
 
-	Loc := l.cLoc + 1
-	start := token.Pos{l.Line, l.Col}
-	//fmt.Println("Loc: ", Loc)
+// readBlockString reads a block string into tok. The current rune is the last quote of the opening """.
+// The only escape sequence in a block string is \""" for """. As in readString, the raw text is sliced from
+// the input unless it contains an escape sequence.
+func (l *Lexer) readBlockString(tok *token.Token) *token.Token {
+	var (
+		raw   strings.Builder
+		built bool // the raw text is being built in raw
+		sLoc  = l.rLoc
+	)
+	text := func() string {
+		if built {
+			return raw.String()
+		}
+		eLoc := l.cLoc
+		if eLoc > len(l.input) {
+			eLoc = len(l.input)
+		}
+		return l.input[sLoc:eLoc]
+	}
 	for {
 		l.readRune()
-		if l.ch == '"' { // "
-			if l.del == token.STRINGDEL {
-				break
-			} else {
-				l.readRune()
-				if l.ch == '"' { // "
-					if l.del == token.STRINGDEL {
-						return l.newToken(token.ILLEGAL, l.ch)
-					}
-					l.readRune()
-					if l.ch == '"' { // "
-						break
-					}
-				}
-				return l.newToken(token.ILLEGAL, l.ch)
+		switch {
+		case l.eof():
+			// unterminated block string
+			tok.Illegal = true
+			tok.Literal = text()
+			return tok
+		case l.ch == '"' && strings.HasPrefix(l.input[l.rLoc:], `""`):
+			tok.Literal = blockStringValue(text())
+			l.readRune()
+			l.readRune()
+			l.readRune() // read over closing """
+			return tok
+		case l.ch == '\\' && strings.HasPrefix(l.input[l.rLoc:], `"""`):
+			if !built {
+				raw.WriteString(text())
+				built = true
 			}
-		}
-		if l.del == token.RAWSTRINGDEL && (l.ch == 10) { // linefeed
+			l.readRune()
+			l.readRune()
+			l.readRune()
+			raw.WriteString(`"""`)
+		case l.ch == '\n':
 			l.Line++
 			l.Col = 0
+			if built {
+				raw.WriteRune(l.ch)
+			}
+		case l.ch == utf8.RuneError && !built:
+			raw.WriteString(text())
+			built = true
+			raw.WriteRune(l.ch)
+		default:
+			if built {
+				raw.WriteRune(l.ch)
+			}
 		}
 	}
-	//fmt.Println("l.cLoc: ", l.cLoc)
-	var eLoc int
-	if l.del == token.RAWSTRINGDEL {
-		eLoc = 2
-		return &token.Token{Cat: token.VALUE, Type: token.RAWSTRING, Literal: l.input[Loc : l.cLoc-eLoc], Loc: start}
+}
+
+// blockStringValue implements BlockStringValue() of the spec. The common indentation of all lines
+// but the first is removed, then leading and trailing blank lines. Lines are joined with a linefeed.
+func blockStringValue(raw string) string {
+	if !strings.ContainsAny(raw, "\r\n") {
+		// a single line has no indentation to remove
+		if len(strings.Trim(raw, " \t")) == 0 {
+			return ""
+		}
+		return raw
+	}
+	lines := strings.Split(strings.ReplaceAll(strings.ReplaceAll(raw, "\r\n", "\n"), "\r", "\n"), "\n")
+	indent := -1
+	for _, line := range lines[1:] {
+		n := len(line) - len(strings.TrimLeft(line, " \t"))
+		if n < len(line) && (indent < 0 || n < indent) {
+			indent = n
+		}
+	}
+	if indent > 0 {
+		for i := 1; i < len(lines); i++ {
+			if len(lines[i]) < indent {
+				lines[i] = ""
+			} else {
+				lines[i] = lines[i][indent:]
+			}
+		}
+	}
+	for len(lines) > 0 && len(strings.Trim(lines[0], " \t")) == 0 {
+		lines = lines[1:]
 	}
-	return &token.Token{Cat: token.VALUE, Type: token.STRING, Literal: l.input[Loc : l.cLoc-eLoc], Loc: start}
+	for len(lines) > 0 && len(strings.Trim(lines[len(lines)-1], " \t")) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// eof reports whether the end of the current source has been reached.
+func (l *Lexer) eof() bool {
+	return l.ch == 0 && l.rLoc >= len(l.input)
+}
+
+// readComment reads a comment up to, but excluding, the line terminator.
+func (l *Lexer) readComment() *token.Token {
+	start := token.Pos{Line: l.Line, Col: l.Col, File: l.file}
+	sLoc := l.rLoc
+	l.readToEol()
+	eLoc := l.cLoc
+	if eLoc > len(l.input) {
+		eLoc = len(l.input)
+	}
+	return &token.Token{Cat: token.NONVALUE, Type: token.COMMENT, Literal: l.input[sLoc:eLoc], Loc: start}
 }
 
 func (l *Lexer) readToEol() {
 	for {
 		l.readRune()
-		if l.ch == '\u000D' || l.ch == '\u000A' {
+		if l.ch == '\u000D' || l.ch == '\u000A' || l.ch == 0 {
 			//l.skipWhitespace()
 			break
 		}
@@ -302,28 +700,36 @@ func (l *Lexer) readToEol() {
 
 func (l *Lexer) newToken(tokenType token.TokenType, ch rune, Loc ...token.Pos) *token.Token {
 
-	if l.bi == 0 {
-		l.bi = 1
-	} else {
-		l.bi = 0
+	loc := token.Pos{Line: l.Line, Col: l.Col, File: l.file}
+	if len(Loc) > 0 {
+		loc = Loc[0]
 	}
-	y := &l.buffer[l.bi]
+	y := l.slot(loc)
 
 	y.Cat = token.NONVALUE
 	y.Type = tokenType
-	y.Literal = string(ch)
-	y.IsScalarType = false
-	y.Illegal = false
-	if len(Loc) > 0 {
-		y.Loc = Loc[0]
+	if ch == l.ch && ch != 0 && ch != utf8.RuneError {
+		y.Literal = l.input[l.cLoc:l.rLoc] // the current rune
 	} else {
-		y.Loc = token.Pos{l.Line, l.Col}
+		y.Literal = string(ch)
 	}
 
 	return y
 
 }
 
+// slot returns the next token of the dual buffer, cleared and located at loc.
+func (l *Lexer) slot(loc token.Pos) *token.Token {
+	if l.bi == 0 {
+		l.bi = 1
+	} else {
+		l.bi = 0
+	}
+	y := &l.buffer[l.bi]
+	*y = token.Token{Loc: loc}
+	return y
+}
+
 // func (l *Lexer) newToken(tokenType token.TokenType, ch rune, Loc ...token.Pos) *token.Token {
 // 	if len(Loc) > 0 {
 // 		return &token.Token{Cat: token.NONVALUE, Type: tokenType, Literal: string(ch), Loc: Loc[0]}
@@ -332,7 +738,7 @@ func (l *Lexer) newToken(tokenType token.TokenType, ch rune, Loc ...token.Pos) *
 // }
 
 func (l *Lexer) GetLoc() *token.Pos {
-	return &token.Pos{l.Line, l.Col}
+	return &token.Pos{Line: l.Line, Col: l.Col, File: l.file}
 }
 
 // func (l *Lexer) SetELoc() {
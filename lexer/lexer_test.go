@@ -1,7 +1,11 @@
 package lexer
 
 import (
-	_ "fmt"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/rosshpayne/graph-sdl/internal/token"
@@ -233,3 +237,338 @@ query getZuckProfile($devicePicSize: Int = 1234) {
 		}
 	}
 }
+
+func TestSources(t *testing.T) {
+	l := NewSources(
+		Source{Name: "a.graphql", Input: "type A {\n  b: B\n}\n# comment without a newline"},
+		Source{Name: "b.graphql", Input: "type B {\n  a: Int }"},
+	)
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+		expectedLoc     token.Pos
+	}{
+		{token.TYPE, "type", token.Pos{Line: 1, Col: 1, File: "a.graphql"}},
+		{token.IDENT, "A", token.Pos{Line: 1, Col: 6, File: "a.graphql"}},
+		{token.LBRACE, "{", token.Pos{Line: 1, Col: 8, File: "a.graphql"}},
+		{token.IDENT, "b", token.Pos{Line: 2, Col: 3, File: "a.graphql"}},
+		{token.COLON, ":", token.Pos{Line: 2, Col: 4, File: "a.graphql"}},
+		{token.IDENT, "B", token.Pos{Line: 2, Col: 6, File: "a.graphql"}},
+		{token.RBRACE, "}", token.Pos{Line: 3, Col: 1, File: "a.graphql"}},
+		{token.TYPE, "type", token.Pos{Line: 1, Col: 1, File: "b.graphql"}},
+		{token.IDENT, "B", token.Pos{Line: 1, Col: 6, File: "b.graphql"}},
+		{token.LBRACE, "{", token.Pos{Line: 1, Col: 8, File: "b.graphql"}},
+		{token.IDENT, "a", token.Pos{Line: 2, Col: 3, File: "b.graphql"}},
+		{token.COLON, ":", token.Pos{Line: 2, Col: 4, File: "b.graphql"}},
+		{token.INT, "Int", token.Pos{Line: 2, Col: 6, File: "b.graphql"}},
+		{token.RBRACE, "}", token.Pos{Line: 2, Col: 10, File: "b.graphql"}},
+		{token.EOF, "\x00", token.Pos{Line: 2, Col: 11, File: "b.graphql"}},
+	}
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q ",
+				i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q ",
+				i, tt.expectedLiteral, tok.Literal)
+		}
+		if loc := tok.Loc; loc.Line != tt.expectedLoc.Line || loc.Col != tt.expectedLoc.Col || loc.File != tt.expectedLoc.File {
+			t.Fatalf("tests[%d] - location wrong. expected=%v, got=%v ",
+				i, tt.expectedLoc, tok.Loc)
+		}
+	}
+}
+
+func TestStrings(t *testing.T) {
+	input := `"" "a\"b\\c\/d" "\b\f\n\r\t" "é\u{1F600}" "😀" "\q" "\uD83D" "\u12"
+"""
+    first
+      second
+
+    third
+  """ """a \"""b""" """line
+    one""" "unterminated
+1`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+		illegal         bool
+		expectedLoc     token.Pos
+	}{
+		{token.STRING, "", false, token.Pos{Line: 1, Col: 1}},
+		{token.STRING, `a"b\c/d`, false, token.Pos{Line: 1, Col: 4}},
+		{token.STRING, "\b\f\n\r\t", false, token.Pos{Line: 1, Col: 17}},
+		{token.STRING, "é😀", false, token.Pos{Line: 1, Col: 30}},
+		{token.STRING, "😀", false, token.Pos{Line: 1, Col: 44}},
+		{token.STRING, "q", true, token.Pos{Line: 1, Col: 51}},
+		{token.STRING, "", true, token.Pos{Line: 1, Col: 56}},
+		{token.STRING, `"`, true, token.Pos{Line: 1, Col: 65}},
+		{token.RAWSTRING, "first\n  second\n\nthird", false, token.Pos{Line: 2, Col: 1}},
+		{token.RAWSTRING, `a """b`, false, token.Pos{Line: 7, Col: 7}},
+		{token.RAWSTRING, "line\none", false, token.Pos{Line: 7, Col: 21}},
+		{token.STRING, `"unterminated`, true, token.Pos{Line: 8, Col: 12}},
+		{token.INT, "1", false, token.Pos{Line: 9, Col: 1}},
+	}
+
+	l := New(input)
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q ",
+				i, tt.expectedType, tok.Type)
+		}
+		if !tt.illegal && tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q ",
+				i, tt.expectedLiteral, tok.Literal)
+		}
+		if tok.Illegal != tt.illegal {
+			t.Fatalf("tests[%d] - illegal wrong. expected=%v, got=%v ",
+				i, tt.illegal, tok.Illegal)
+		}
+		if loc := tok.Loc; loc.Line != tt.expectedLoc.Line || loc.Col != tt.expectedLoc.Col || loc.File != tt.expectedLoc.File {
+			t.Fatalf("tests[%d] - location wrong. expected=%v, got=%v ",
+				i, tt.expectedLoc, tok.Loc)
+		}
+	}
+}
+
+func TestNumbers(t *testing.T) {
+	input := `0 -0 123 -45 1.5 -1.5E-3 1e10 2E+2 0123 123abc 1. 1e 1.5.2 - x`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+		expectedErr     string
+	}{
+		{token.INT, "0", ""},
+		{token.INT, "-0", ""},
+		{token.INT, "123", ""},
+		{token.INT, "-45", ""},
+		{token.FLOAT, "1.5", ""},
+		{token.FLOAT, "-1.5E-3", ""},
+		{token.FLOAT, "1e10", ""},
+		{token.FLOAT, "2E+2", ""},
+		{token.INT, "0123", `Invalid number "0123", leading zero`},
+		{token.INT, "123abc", `Invalid number "123abc", unexpected character 'a' after the number`},
+		{token.FLOAT, "1.", `Invalid number "1.", expected a digit after the decimal point`},
+		{token.FLOAT, "1e", `Invalid number "1e", expected a digit in the exponent`},
+		{token.FLOAT, "1.5.2", `Invalid number "1.5.2", unexpected character '.' after the number`},
+		{token.INT, "-", `Invalid number "-", expected a digit`},
+		{token.IDENT, "x", ""},
+	}
+
+	l := New(input)
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q ",
+				i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q ",
+				i, tt.expectedLiteral, tok.Literal)
+		}
+		if tok.Illegal != (len(tt.expectedErr) > 0) || tok.Err != tt.expectedErr {
+			t.Fatalf("tests[%d] - error wrong. expected=%q, got=%q ",
+				i, tt.expectedErr, tok.Err)
+		}
+	}
+}
+
+func TestComments(t *testing.T) {
+	input := "# leading\ntype A { # after brace\n  a: Int #\n}"
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+		expectedLoc     token.Pos
+	}{
+		{token.COMMENT, " leading", token.Pos{Line: 1, Col: 1}},
+		{token.TYPE, "type", token.Pos{Line: 2, Col: 1}},
+		{token.IDENT, "A", token.Pos{Line: 2, Col: 6}},
+		{token.LBRACE, "{", token.Pos{Line: 2, Col: 8}},
+		{token.COMMENT, " after brace", token.Pos{Line: 2, Col: 10}},
+		{token.IDENT, "a", token.Pos{Line: 3, Col: 3}},
+		{token.COLON, ":", token.Pos{Line: 3, Col: 4}},
+		{token.INT, "Int", token.Pos{Line: 3, Col: 6}},
+		{token.COMMENT, "", token.Pos{Line: 3, Col: 10}},
+		{token.RBRACE, "}", token.Pos{Line: 4, Col: 1}},
+		{token.EOF, "\x00", token.Pos{Line: 4, Col: 2}},
+	}
+
+	l := New(input).KeepComments()
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q ",
+				i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q ",
+				i, tt.expectedLiteral, tok.Literal)
+		}
+		if loc := tok.Loc; loc.Line != tt.expectedLoc.Line || loc.Col != tt.expectedLoc.Col || loc.File != tt.expectedLoc.File {
+			t.Fatalf("tests[%d] - location wrong. expected=%v, got=%v ",
+				i, tt.expectedLoc, tok.Loc)
+		}
+	}
+}
+
+func TestSpans(t *testing.T) {
+	input := "type A {\n  b: [Int!] \"x\"\n}\n\"\"\"\n  two\n  lines\n\"\"\" ..."
+
+	tests := []struct {
+		expectedType  token.TokenType
+		expectedStart token.Pos
+		expectedEnd   token.Pos
+	}{
+		{token.TYPE, token.Pos{Line: 1, Col: 1, Offset: 0}, token.Pos{Line: 1, Col: 5, Offset: 4}},
+		{token.IDENT, token.Pos{Line: 1, Col: 6, Offset: 5}, token.Pos{Line: 1, Col: 7, Offset: 6}},
+		{token.LBRACE, token.Pos{Line: 1, Col: 8, Offset: 7}, token.Pos{Line: 1, Col: 9, Offset: 8}},
+		{token.IDENT, token.Pos{Line: 2, Col: 3, Offset: 11}, token.Pos{Line: 2, Col: 4, Offset: 12}},
+		{token.COLON, token.Pos{Line: 2, Col: 4, Offset: 12}, token.Pos{Line: 2, Col: 5, Offset: 13}},
+		{token.LBRACKET, token.Pos{Line: 2, Col: 6, Offset: 14}, token.Pos{Line: 2, Col: 7, Offset: 15}},
+		{token.INT, token.Pos{Line: 2, Col: 7, Offset: 15}, token.Pos{Line: 2, Col: 10, Offset: 18}},
+		{token.BANG, token.Pos{Line: 2, Col: 10, Offset: 18}, token.Pos{Line: 2, Col: 11, Offset: 19}},
+		{token.RBRACKET, token.Pos{Line: 2, Col: 11, Offset: 19}, token.Pos{Line: 2, Col: 12, Offset: 20}},
+		{token.STRING, token.Pos{Line: 2, Col: 13, Offset: 21}, token.Pos{Line: 2, Col: 16, Offset: 24}},
+		{token.RBRACE, token.Pos{Line: 3, Col: 1, Offset: 25}, token.Pos{Line: 3, Col: 2, Offset: 26}},
+		{token.RAWSTRING, token.Pos{Line: 4, Col: 1, Offset: 27}, token.Pos{Line: 7, Col: 4, Offset: 48}},
+		{token.EXPAND, token.Pos{Line: 7, Col: 5, Offset: 49}, token.Pos{Line: 7, Col: 8, Offset: 52}},
+		{token.EOF, token.Pos{Line: 7, Col: 8, Offset: 52}, token.Pos{Line: 7, Col: 8, Offset: 52}},
+	}
+
+	l := New(input)
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q ",
+				i, tt.expectedType, tok.Type)
+		}
+		if tok.Loc != tt.expectedStart {
+			t.Fatalf("tests[%d] - start wrong. expected=%v, got=%v ",
+				i, tt.expectedStart, tok.Loc)
+		}
+		if tok.End != tt.expectedEnd {
+			t.Fatalf("tests[%d] - end wrong. expected=%v, got=%v ",
+				i, tt.expectedEnd, tok.End)
+		}
+	}
+}
+
+var update = flag.Bool("update", false, "rewrite the golden token streams in testdata")
+
+// TestGolden compares the token stream of each testdata/<name>.graphql, one token per line of start, end, type and
+// literal, with testdata/<name>.golden. The golden files were generated by the rune-based lexer this lexer replaced.
+// Run with -update to rewrite them after a deliberate change.
+func TestGolden(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join("testdata", "*.graphql"))
+	if err != nil || len(inputs) == 0 {
+		t.Fatalf("no inputs in testdata: %v", err)
+	}
+	for _, in := range inputs {
+		input, err := os.ReadFile(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var b strings.Builder
+		l := New(string(input))
+		for i := 0; i <= len(input); i++ {
+			tok := l.NextToken()
+			fmt.Fprintf(&b, "%d:%d-%d:%d %s %q\n", tok.Loc.Line, tok.Loc.Col, tok.End.Line, tok.End.Col, tok.Type, tok.Literal)
+			if tok.Type == token.EOF {
+				break
+			}
+		}
+		golden := strings.TrimSuffix(in, ".graphql") + ".golden"
+		if *update {
+			if err := os.WriteFile(golden, []byte(b.String()), 0644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		want, err := os.ReadFile(golden)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, exp := strings.Split(b.String(), "\n"), strings.Split(string(want), "\n")
+		for i := 0; i < len(got) || i < len(exp); i++ {
+			var g, e string
+			if i < len(got) {
+				g = got[i]
+			}
+			if i < len(exp) {
+				e = exp[i]
+			}
+			if g != e {
+				t.Errorf("%s:%d - token wrong. expected=%q, got=%q ", golden, i+1, e, g)
+				break
+			}
+		}
+	}
+}
+
+func TestAllocs(t *testing.T) {
+	input := `type Person @key(fields: "id") { id: ID! age(unit: Int = 12, scale: Float = -1.5e3): [Int!] "name" name: String }`
+	l := New(input)
+	allocs := testing.AllocsPerRun(100, func() {
+		if tok := l.NextToken(); tok.Type == token.EOF {
+			l = New(input)
+		}
+	})
+	// one lexer is allocated per pass of the input
+	if allocs > 0.1 {
+		t.Fatalf("expected no allocations per token, got %v", allocs)
+	}
+}
+
+// schema returns a document of n object types, each with a description, an argument with a default and a
+// directive, followed by an enum, in the shape of a large generated schema.
+func schema(n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, `"A type of %d things"
+type Type%d implements Node @key(fields: "id") {
+  # the identity
+  id: ID!
+  "how many"
+  count(first: Int = 10, after: String = "c%d"): [Float!]! @deprecated(reason: "use total")
+  """
+  Block description
+  of the total
+  """
+  total: Float
+}
+
+enum Kind%d { A B C }
+`, i, i, i, i)
+	}
+	return b.String()
+}
+
+func BenchmarkNextToken(b *testing.B) {
+	input := schema(1000)
+	b.SetBytes(int64(len(input)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l := New(input)
+		for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		}
+	}
+}
+
+func BenchmarkNextTokenComments(b *testing.B) {
+	input := schema(1000)
+	b.SetBytes(int64(len(input)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l := New(input).KeepComments()
+		for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		}
+	}
+}
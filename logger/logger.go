@@ -0,0 +1,105 @@
+// Package logger defines the Logger through which the parser, and the ast and database packages it drives, report
+// their progress. Logging is silent unless a Logger is set e.g. with parser.(*Parser).SetLogger.
+package logger
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// Level is the severity of a log entry.
+type Level int8
+
+const (
+	Debug Level = iota // detail of parsing and validation, for diagnosing the library itself
+	Info               // progress of a document through its phases
+	Warn               // an unexpected but recoverable condition
+	Error              // a failure e.g. of the database
+)
+
+func (l Level) String() string {
+	switch l {
+	case Debug:
+		return "DEBUG"
+	case Info:
+		return "INFO"
+	case Warn:
+		return "WARN"
+	case Error:
+		return "ERROR"
+	}
+	return fmt.Sprintf("Level(%d)", int8(l))
+}
+
+// Field is a named value that qualifies a log entry.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// Keys of the fields attached to log entries.
+const (
+	DocumentKey = "document" // document being parsed or validated
+	TypeKey     = "type"     // type, or statement, being processed
+	PhaseKey    = "phase"    // parse, resolve, validate or persist
+)
+
+// Document returns the field that names the document being processed.
+func Document(name string) Field {
+	return Field{Key: DocumentKey, Value: name}
+}
+
+// Type returns the field that names the type being processed.
+func Type(name string) Field {
+	return Field{Key: TypeKey, Value: name}
+}
+
+// Phase returns the field that names the phase of processing.
+func Phase(name string) Field {
+	return Field{Key: PhaseKey, Value: name}
+}
+
+// Any returns a field with any key and value.
+func Any(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Logger receives log entries. An implementation is typically an adapter to the logging library of the
+// application that embeds the parser.
+type Logger interface {
+	Log(level Level, msg string, fields ...Field)
+}
+
+// Nop is a Logger that discards every entry. It is the default.
+var Nop Logger = nop{}
+
+type nop struct{}
+
+func (nop) Log(Level, string, ...Field) {}
+
+// New returns a Logger that writes entries of level min and above to l, one per line, in the form
+//
+//	INFO parsed statement document=DefaultDoc type=Person
+func New(l *log.Logger, min Level) Logger {
+	return &stdLogger{l: l, min: min}
+}
+
+type stdLogger struct {
+	l   *log.Logger
+	min Level
+}
+
+func (s *stdLogger) Log(level Level, msg string, fields ...Field) {
+	if level < s.min {
+		return
+	}
+	var b strings.Builder
+	b.WriteString(level.String())
+	b.WriteByte(' ')
+	b.WriteString(msg)
+	for _, f := range fields {
+		fmt.Fprintf(&b, " %s=%v", f.Key, f.Value)
+	}
+	s.l.Output(2, b.String())
+}
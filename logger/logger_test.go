@@ -0,0 +1,20 @@
+package logger
+
+import (
+	"bytes"
+	"log"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(log.New(&buf, "", 0), Info)
+
+	l.Log(Debug, "fetch type", Type("Person"))
+	l.Log(Info, "parse document", Document("Films"), Phase("parse"), Any("cached", 3))
+
+	expected := "INFO parse document document=Films phase=parse cached=3\n"
+	if got := buf.String(); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
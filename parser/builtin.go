@@ -0,0 +1,29 @@
+package parser
+
+import (
+	"github.com/rosshpayne/graph-sdl/ast"
+	"github.com/rosshpayne/graph-sdl/internal/db"
+)
+
+// builtinDirectives are the directives defined by the spec. Like the built-in scalars they are available to every document
+// without a definition, and cannot be redefined.
+var builtinDirectives = map[string]string{
+	"@skip":        `directive @skip(if: Boolean!) on FIELD | FRAGMENT_SPREAD | INLINE_FRAGMENT`,
+	"@include":     `directive @include(if: Boolean!) on FIELD | FRAGMENT_SPREAD | INLINE_FRAGMENT`,
+	"@deprecated":  `directive @deprecated(reason: String = "No longer supported") on FIELD_DEFINITION | ARGUMENT_DEFINITION | INPUT_FIELD_DEFINITION | ENUM_VALUE`,
+	"@specifiedBy": `directive @specifiedBy(url: String!) on SCALAR`,
+}
+
+// IsBuiltinDirective reports whether name e.g. "@deprecated" is a directive defined by the spec.
+func IsBuiltinDirective(name ast.NameValue_) bool {
+	_, ok := builtinDirectives[name.String()]
+	return ok
+}
+
+// fetchSDL returns the definition of the named type, which is either built-in or read from the database.
+func fetchSDL(name string) (string, error) {
+	if sdl, ok := builtinDirectives[name]; ok {
+		return sdl, nil
+	}
+	return db.DBFetch(name)
+}
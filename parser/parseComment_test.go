@@ -0,0 +1,96 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/rosshpayne/graph-sdl/lexer"
+)
+
+func TestComments(t *testing.T) {
+
+	input := `
+# Pets are owned by people
+# and have a name
+type Pet38 {
+  name: String # as registered
+  "age in years"
+  age(
+    # rounded down
+    unit: Int = 1 # years
+  ): Int
+  # weight: Float
+}
+
+enum Kind38 {
+  # the usual
+  DOG CAT # or a cat
+  BIRD
+# end of Kind38
+# end of document
+}
+`
+	expectedDoc := `
+enum Kind38 {
+# the usual
+DOG
+CAT # or a cat
+BIRD
+# end of Kind38
+# end of document
+}
+
+# Pets are owned by people
+# and have a name
+type Pet38 {
+name : String # as registered
+"age in years"
+age(
+# rounded down
+unit : Int = 1 # years
+) : Int
+# weight: Float
+}
+`
+	l := lexer.New(input).KeepComments()
+	p := New(l)
+	d, errs := p.ParseDocument()
+	for _, v := range errs {
+		t.Errorf(`Unexpected error: %s`, v.Error())
+	}
+	if compare(d.String(), expectedDoc) {
+		t.Errorf("Got:      [%s] \n", trimWS(d.String()))
+		t.Errorf("Expected: [%s] \n", trimWS(expectedDoc))
+		t.Errorf(`Unexpected: program.String() wrong. `)
+	}
+	// the comments survive a round trip
+	l = lexer.New(d.String()).KeepComments()
+	p = New(l)
+	d2, _ := p.ParseDocument()
+	if d2.String() != d.String() {
+		t.Errorf("Got:      [%s] \n", d2.String())
+		t.Errorf("Expected: [%s] \n", d.String())
+	}
+}
+
+func TestCommentsDropped(t *testing.T) {
+
+	input := `
+# not kept
+type Pet38a {
+  name: String # not kept
+}
+`
+	expectedDoc := `type Pet38a {name : String}`
+
+	l := lexer.New(input)
+	p := New(l)
+	d, errs := p.ParseDocument()
+	for _, v := range errs {
+		t.Errorf(`Unexpected error: %s`, v.Error())
+	}
+	if compare(d.String(), expectedDoc) {
+		t.Errorf("Got:      [%s] \n", trimWS(d.String()))
+		t.Errorf("Expected: [%s] \n", trimWS(expectedDoc))
+		t.Errorf(`Unexpected: program.String() wrong. `)
+	}
+}
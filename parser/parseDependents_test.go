@@ -0,0 +1,183 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/rosshpayne/graph-sdl/ast"
+	"github.com/rosshpayne/graph-sdl/internal/db"
+	"github.com/rosshpayne/graph-sdl/lexer"
+)
+
+func TestDependents(t *testing.T) {
+
+	stored := `
+interface Dep44I {
+  a: Int
+  b: String
+}
+
+type Dep44O implements Dep44I {
+  a: Int
+  b: String
+}
+`
+	l := lexer.New(stored)
+	p := New(l)
+	_, errs := p.ParseDocument()
+	for _, err := range errs {
+		t.Fatalf(`Unexpected error: %s`, err)
+	}
+
+	// adding a field to the interface invalidates the stored object
+	changed := `
+interface Dep44I {
+  a: Int
+  b: String
+  c: Float
+}
+`
+	l = lexer.New(changed)
+	p = New(l)
+	d, errs := p.ParseDocument()
+	var found bool
+	for _, err := range errs {
+		var diag *ast.Diagnostic
+		if errors.As(err, &diag) && diag.Code == ast.CodeDependent {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf(`Expected a %s error, got %v`, ast.CodeDependent, errs)
+	}
+	if len(d.Results) != 1 || d.Results[0].Outcome != ast.Rejected {
+		t.Errorf(`Expected Dep44I to be rejected, got %v`, d.Results)
+	}
+
+	// when dependents do not block, the change is applied with a warning
+	l = lexer.New(changed)
+	p = New(l).SetBlockOnDependents(false).SetDryRun(true)
+	d, errs = p.ParseDocument()
+	found = false
+	for _, err := range errs {
+		var diag *ast.Diagnostic
+		if errors.As(err, &diag) && diag.Code == ast.CodeDependent {
+			found = diag.Severity == ast.SevWarning
+		}
+	}
+	if !found {
+		t.Errorf(`Expected a %s warning, got %v`, ast.CodeDependent, errs)
+	}
+	if len(d.Results) != 1 || d.Results[0].Outcome != ast.Updated {
+		t.Errorf(`Expected Dep44I to be updated, got %v`, d.Results)
+	}
+}
+
+func TestDependentsErrLimit(t *testing.T) {
+
+	for _, name := range []string{"Lim44I", "Lim44O"} {
+		if err := db.DeleteType(name); err != nil {
+			t.Errorf(`Not expected Error =[%q]`, err.Error())
+		}
+	}
+	stored := `
+interface Lim44I {
+  a: Int
+}
+
+type Lim44O implements Lim44I {
+  a: Int
+}
+`
+	_, errs := New(lexer.New(stored)).ParseDocument()
+	for _, err := range errs {
+		t.Fatalf(`Unexpected error: %s`, err)
+	}
+
+	// the change invalidates the stored object, but validation stops at the error limit before dependents are checked
+	var changed strings.Builder
+	changed.WriteString("interface Lim44I {\n  a: Int\n  c: Float\n}\n")
+	for i := 0; i <= DefaultErrLimit; i++ {
+		fmt.Fprintf(&changed, "type Lim44Bad%d {\n  a: Lim44Undefined\n}\n", i)
+	}
+	d, _ := New(lexer.New(changed.String())).ParseDocument()
+	for _, r := range d.Results {
+		if r.Name == "Lim44I" && r.Outcome != ast.Skipped {
+			t.Errorf(`Expected Lim44I to be skipped, got %s`, r.Outcome)
+		}
+	}
+	row, err := db.DBFetchRow("Lim44I")
+	if err != nil {
+		t.Fatalf(`Unexpected error: %s`, err)
+	}
+	if strings.Contains(row.Stmt, "c:") {
+		t.Errorf(`Expected the stored Lim44I to be unchanged, got %s`, row.Stmt)
+	}
+}
+
+func TestDependentsWarning(t *testing.T) {
+
+	for _, name := range []string{"Warn44I", "Warn44O"} {
+		if err := db.DeleteType(name); err != nil {
+			t.Errorf(`Not expected Error =[%q]`, err.Error())
+		}
+	}
+	stored := `
+interface Warn44I {
+  a: Int
+}
+
+type Warn44O implements Warn44I {
+  a: Int
+}
+`
+	_, errs := New(lexer.New(stored)).ParseDocument()
+	for _, err := range errs {
+		t.Fatalf(`Unexpected error: %s`, err)
+	}
+	// when dependents do not block, the change is written and the invalidated dependent is reported as a warning
+	d, errs := New(lexer.New(`interface Warn44I { a: Int c: Float }`)).SetBlockOnDependents(false).ParseDocument()
+	var found bool
+	for _, err := range errs {
+		var diag *ast.Diagnostic
+		if !errors.As(err, &diag) || diag.Code != ast.CodeDependent || diag.Severity != ast.SevWarning {
+			t.Errorf(`Unexpected error: %s`, err)
+			continue
+		}
+		found = diag.TypeName == "Warn44I" && strings.Contains(diag.Message, `"Warn44O"`)
+	}
+	if !found {
+		t.Errorf(`Expected a %s warning for Warn44O, got %v`, ast.CodeDependent, errs)
+	}
+	if len(d.Results) != 1 || d.Results[0].Outcome != ast.Updated {
+		t.Errorf(`Expected Warn44I to be updated, got %v`, d.Results)
+	}
+	row, err := db.DBFetchRow("Warn44I")
+	if err != nil {
+		t.Fatalf(`Unexpected error: %s`, err)
+	}
+	if !strings.Contains(row.Stmt, "c:") {
+		t.Errorf(`Expected the stored Warn44I to be changed, got %s`, row.Stmt)
+	}
+}
+
+func TestMentions(t *testing.T) {
+
+	stmt := `type Dep44X implements Dep44I @dir44 { a: [Dep44E!] b(x: Dep44In = {e: Dep44Enum}): Int }`
+	for name, expected := range map[string]bool{
+		"Dep44I":   true,
+		"Dep44E":   true,
+		"Dep44In":  true,
+		"Dep44Enu": false,
+		"Dep44":    false,
+		"@dir44":   true,
+		"@dir4":    false,
+		"X":        false,
+	} {
+		if got := mentions(stmt, name); got != expected {
+			t.Errorf(`Expected mentions of %s to be %v, got %v`, name, expected, got)
+		}
+	}
+}
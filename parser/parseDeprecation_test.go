@@ -0,0 +1,177 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/rosshpayne/graph-sdl/ast"
+	"github.com/rosshpayne/graph-sdl/internal/db"
+	"github.com/rosshpayne/graph-sdl/lexer"
+)
+
+func TestDeprecationMembers(t *testing.T) {
+
+	input := `
+type Dep49 {
+  name: String @deprecated(reason: "Use fullName")
+  fullName: String
+  age(unit: String @deprecated, scale: Int): Int @deprecated
+}
+`
+	l := lexer.New(input)
+	p := New(l)
+	stmt := p.ParseStatement()
+	if len(p.perror) > 0 {
+		t.Fatalf(`Unexpected error: %s`, p.perror[0])
+	}
+	expected := []ast.Deprecation{
+		{Type: "Dep49", Kind: "Field", Path: "name", Reason: "Use fullName"},
+		{Type: "Dep49", Kind: "Argument", Path: "age(unit)", Reason: ast.DefaultDeprecationReason},
+		{Type: "Dep49", Kind: "Field", Path: "age", Reason: ast.DefaultDeprecationReason},
+	}
+	got := ast.Deprecations(stmt)
+	if len(got) != len(expected) {
+		t.Fatalf(`Expected %d deprecations, got %v`, len(expected), got)
+	}
+	for _, ex := range expected {
+		found := false
+		for _, d := range got {
+			if d == ex {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf(`Expected deprecation %v`, ex)
+		}
+	}
+}
+
+func TestDeprecationPolicy(t *testing.T) {
+
+	if err := db.DeleteType("Pol49"); err != nil {
+		t.Errorf(`Not expected Error =[%q]`, err.Error())
+	}
+	apply := func(input string) (*ast.Document, []error) {
+		p := New(lexer.New(input)).SetDeprecationPolicy(1, 0)
+		return p.ParseDocument()
+	}
+	_, errs := apply(`type Pol49 { a: Int b: Int }`)
+	for _, err := range errs {
+		t.Fatalf(`Unexpected error: %s`, err)
+	}
+	// b is not deprecated
+	d, errs := apply(`type Pol49 { a: Int }`)
+	var found bool
+	for _, err := range errs {
+		var diag *ast.Diagnostic
+		if errors.As(err, &diag) && diag.Code == ast.CodeDeprecated {
+			found = true
+		}
+	}
+	if !found || d.Results[0].Outcome != ast.Rejected {
+		t.Errorf(`Expected removal of b to be rejected, got %v`, errs)
+	}
+	// deprecate b in revision 2
+	d, errs = apply(`type Pol49 { a: Int b: Int @deprecated(reason: "Use a") }`)
+	for _, err := range errs {
+		t.Fatalf(`Unexpected error: %s`, err)
+	}
+	if len(d.Deprecations) != 1 || d.Deprecations[0].Path != "b" || d.Deprecations[0].Reason != "Use a" || d.Deprecations[0].Revision != 2 {
+		t.Errorf(`Expected b to be deprecated in revision 2, got %v`, d.Deprecations)
+	}
+	// b has been deprecated for one version
+	d, errs = apply(`type Pol49 { a: Int }`)
+	for _, err := range errs {
+		t.Errorf(`Unexpected error: %s`, err)
+	}
+	if d.Results[0].Outcome != ast.Updated {
+		t.Errorf(`Expected removal of b to be applied, got %s`, d.Results[0].Outcome)
+	}
+}
+
+func TestStoredDeprecations(t *testing.T) {
+
+	if err := db.DeleteType("Sto49"); err != nil {
+		t.Errorf(`Not expected Error =[%q]`, err.Error())
+	}
+	_, errs := New(lexer.New(`type Sto49 { a: Int b: Int @deprecated(reason: "Use a") }`)).ParseDocument()
+	for _, err := range errs {
+		t.Fatalf(`Unexpected error: %s`, err)
+	}
+	d, err := New(lexer.New(``)).StoredDeprecations()
+	if err != nil {
+		t.Fatalf(`Unexpected error: %s`, err)
+	}
+	var found bool
+	for _, v := range d {
+		if v.Type != "Sto49" {
+			continue
+		}
+		found = true
+		if v.Kind != "Field" || v.Path != "b" || v.Reason != "Use a" || v.Revision != 1 || v.Since.IsZero() {
+			t.Errorf(`Expected b of Sto49 to be deprecated in revision 1, got %v`, v)
+		}
+	}
+	if !found {
+		t.Errorf(`Expected a deprecation of Sto49, got %v`, d)
+	}
+}
+
+func TestDeprecationPolicyLegacyRow(t *testing.T) {
+
+	if err := db.DeleteType("Leg49"); err != nil {
+		t.Errorf(`Not expected Error =[%q]`, err.Error())
+	}
+	// a row written before deprecations were recorded
+	p := New(lexer.New(`type Leg49 { a: Int b: Int @deprecated }`))
+	stmt := p.ParseStatement()
+	if len(p.perror) > 0 {
+		t.Fatalf(`Unexpected error: %s`, p.perror[0])
+	}
+	db.SetDocument(defaultDoc)
+	row := db.NewRow("Leg49", stmt, nil)
+	row.Dp = nil
+	if err := db.Persist(row, stmt); err != nil {
+		t.Fatalf(`Unexpected error: %s`, err)
+	}
+	// b is deprecated in the stored statement, so has been deprecated for one version
+	d, errs := New(lexer.New(`type Leg49 { a: Int }`)).SetDeprecationPolicy(1, 0).ParseDocument()
+	for _, err := range errs {
+		t.Errorf(`Unexpected error: %s`, err)
+	}
+	if d.Results[0].Outcome != ast.Updated {
+		t.Errorf(`Expected removal of b to be applied, got %s`, d.Results[0].Outcome)
+	}
+}
+
+func TestDeprecationPolicyErrLimit(t *testing.T) {
+
+	if err := db.DeleteType("Lim49"); err != nil {
+		t.Errorf(`Not expected Error =[%q]`, err.Error())
+	}
+	_, errs := New(lexer.New(`type Lim49 { a: Int b: Int }`)).ParseDocument()
+	for _, err := range errs {
+		t.Fatalf(`Unexpected error: %s`, err)
+	}
+	// b is not deprecated, and validation stops at the error limit before removals are checked
+	var input strings.Builder
+	input.WriteString("type Lim49 { a: Int }\n")
+	for i := 0; i <= DefaultErrLimit; i++ {
+		fmt.Fprintf(&input, "type Lim49Bad%d { a: Lim49Undefined }\n", i)
+	}
+	d, _ := New(lexer.New(input.String())).SetDeprecationPolicy(1, 0).ParseDocument()
+	for _, r := range d.Results {
+		if r.Name == "Lim49" && r.Outcome != ast.Skipped {
+			t.Errorf(`Expected Lim49 to be skipped, got %s`, r.Outcome)
+		}
+	}
+	row, err := db.DBFetchRow("Lim49")
+	if err != nil {
+		t.Fatalf(`Unexpected error: %s`, err)
+	}
+	if !strings.Contains(row.Stmt, "b:") {
+		t.Errorf(`Expected the stored Lim49 to keep b, got %s`, row.Stmt)
+	}
+}
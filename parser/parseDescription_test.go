@@ -0,0 +1,142 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/rosshpayne/graph-sdl/lexer"
+)
+
+func TestDescription1(t *testing.T) {
+
+	input := `
+"A person of interest"
+type DescPerson {
+  "full name"
+  name(
+  """
+  format of name
+  """
+  fmt: DescNameFmt = FULL): String
+  age: Int
+}
+
+"""
+How a name is displayed
+"""
+enum DescNameFmt {
+  "first and last names"
+  FULL
+  SHORT
+}
+
+"Search criteria"
+input DescCriteria {
+  "minimum age"
+  minAge: Int
+}
+`
+	expectedDoc := `"Search criteria" input DescCriteria {"minimum age" minAge:Int}
+	"How a name is displayed" enum DescNameFmt {"first and last names" FULL SHORT}
+	"A person of interest" type DescPerson {"full name" name( "format of name" fmt:DescNameFmt=FULL):String age:Int}`
+
+	l := lexer.New(input)
+	p := New(l)
+	d, errs := p.ParseDocument()
+	if len(errs) > 0 {
+		t.Errorf("Unexpected, should be 0 errors, got %d", len(errs))
+		for _, v := range errs {
+			t.Errorf(`Unexpected error: %s`, v.Error())
+		}
+	}
+	if compare(d.String(), expectedDoc) {
+		t.Errorf("Got:      [%s] \n", trimWS(d.String()))
+		t.Errorf("Expected: [%s] \n", trimWS(expectedDoc))
+		t.Errorf(`Unexpected: program.String() wrong. `)
+	}
+}
+
+func TestDescription2(t *testing.T) {
+
+	input := `
+"""
+Marks a field, see \""" in the docs
+"""
+directive @desc26(
+  "why it is marked"
+  why: String
+) on FIELD_DEFINITION
+
+"An instant in time"
+scalar Desc26Time
+
+"Something with a name"
+interface Desc26Named {
+  "the name"
+  name: String
+}
+
+type Desc26Query implements Desc26Named {
+  name: String @desc26(why: "x")
+  at: Desc26Time
+}
+
+"""
+Either
+of the two
+"""
+union Desc26Result = Desc26Query
+
+"The entry points"
+schema {
+  query: Desc26Query
+}
+`
+	expectedDoc := `"""Marks a field, see \""" in the docs""" directive @desc26 ( "why it is marked" why : String ) on | FIELD_DEFINITION
+	"Something with a name" interface Desc26Named { "the name" name : String }
+	type Desc26Query implements Desc26Named { name : String @desc26(why:"x") at : Desc26Time }
+	"""Either of the two""" union Desc26Result =| Desc26Query
+	"An instant in time" scalar Desc26Time
+	"The entry points" schema { query : Desc26Query }`
+
+	l := lexer.New(input)
+	p := New(l).SetDryRun(true)
+	d, errs := p.ParseDocument()
+	if len(errs) > 0 {
+		t.Errorf("Unexpected, should be 0 errors, got %d", len(errs))
+		for _, v := range errs {
+			t.Errorf(`Unexpected error: %s`, v.Error())
+		}
+	}
+	if compare(d.String(), expectedDoc) {
+		t.Errorf("Got:      [%s] \n", trimWS(d.String()))
+		t.Errorf("Expected: [%s] \n", trimWS(expectedDoc))
+		t.Errorf(`Unexpected: program.String() wrong. `)
+	}
+}
+
+func TestDescriptionOnExtend(t *testing.T) {
+
+	input := `
+"not permitted"
+extend type DescPerson {
+  height: Float
+}
+`
+	var expectedErr [1]string
+	expectedErr[0] = `A description is not permitted on a type extension at line: 3 column: 1`
+
+	l := lexer.New(input)
+	p := New(l)
+	_, errs := p.ParseDocument()
+	for _, ex := range expectedErr {
+		found := false
+		for _, err := range errs {
+			if trimWS(err.Error()) == trimWS(ex) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf(`Expected Error = [%q]`, ex)
+		}
+	}
+}
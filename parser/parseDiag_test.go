@@ -0,0 +1,70 @@
+package parser
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rosshpayne/graph-sdl/ast"
+	"github.com/rosshpayne/graph-sdl/lexer"
+)
+
+func TestDiagnostics(t *testing.T) {
+
+	input := `
+type Diag34A {
+  a: Int
+  a: Float
+  b: Diag34Unknown
+}
+
+type Diag34B {
+  a Int
+}
+`
+	type expected struct {
+		code     ast.ErrCode
+		line     int
+		col      int
+		typeName ast.NameValue_
+		related  int
+	}
+	expectedDiag := []expected{
+		{code: ast.CodeDuplicate, line: 4, col: 3, typeName: "Diag34A", related: 1},
+		{code: ast.CodeType, line: 5, col: 6, typeName: "Diag34A"},
+		{code: ast.CodeSyntax, line: 9, col: 5},
+	}
+
+	l := lexer.New(input)
+	p := New(l)
+	_, errs := p.ParseDocument()
+
+	var resolveErr bool
+	for _, ex := range expectedDiag {
+		found := false
+		for _, err := range errs {
+			var d *ast.Diagnostic
+			if !errors.As(err, &d) {
+				t.Errorf(`Expected a Diagnostic, got [%q]`, err.Error())
+				continue
+			}
+			if d.Code == ex.code && d.Span.Start.Line == ex.line && d.Span.Start.Column == ex.col {
+				found = true
+				if d.TypeName != ex.typeName {
+					t.Errorf(`Expected type name "%s" for [%q], got "%s"`, ex.typeName, d.Error(), d.TypeName)
+				}
+				if len(d.Related) != ex.related {
+					t.Errorf(`Expected %d related locations for [%q], got %d`, ex.related, d.Error(), len(d.Related))
+				}
+			}
+			if errors.Is(err, TypeResolveErr) {
+				resolveErr = true
+			}
+		}
+		if !found {
+			t.Errorf(`Expected %s diagnostic at line: %d column: %d`, ex.code, ex.line, ex.col)
+		}
+	}
+	if !resolveErr {
+		t.Errorf(`Expected errors.Is(err, TypeResolveErr) for "Diag34Unknown"`)
+	}
+}
@@ -132,8 +132,8 @@ extend input ExampleInputObjectDirective2 @ june (asdf:234)
 `
 	var expectedErr [3]string
 	expectedErr[0] = `Duplicate Directive name "@june" at line: 9, column: 45`
-	expectedErr[1] = `extend for type "ExampleInputObjectDirective2" contains no changes at line: 10, column: 0`
-	expectedErr[2] = `Directive "@june" is not registered for INPUT_OBJECT usage at line: 4 column: 38`
+	expectedErr[1] = `Directive "@june" is not registered for INPUT_OBJECT usage at line: 4 column: 38`
+	expectedErr[2] = `Directive "@june" is not registered for INPUT_OBJECT usage at line: 9 column: 45`
 
 	l := lexer.New(input)
 	p := New(l)
@@ -1667,3 +1667,144 @@ type Query {
 		t.Errorf(`Unexpected: program.String() wrong. `)
 	}
 }
+
+func TestDirectiveRepeatable(t *testing.T) {
+
+	input := `
+directive @tag28(name: String!) repeatable on FIELD_DEFINITION | OBJECT
+
+type Product28 @tag28(name: "retail") @tag28(name: "public") {
+	sku: String @tag28(name: "key") @tag28(name: "indexed")
+}
+`
+	expectedDoc := `directive @tag28(name:String!) repeatable on | FIELD_DEFINITION | OBJECT
+	type Product28 @tag28(name:"retail") @tag28(name:"public") {sku:String @tag28(name:"key") @tag28(name:"indexed")}`
+
+	l := lexer.New(input)
+	p := New(l)
+	d, errs := p.ParseDocument()
+	if len(errs) > 0 {
+		t.Errorf("Unexpected, should be 0 errors, got %d", len(errs))
+		for _, v := range errs {
+			t.Errorf(`Unexpected error: %s`, v.Error())
+		}
+	}
+	if compare(d.String(), expectedDoc) {
+		t.Errorf("Got:      [%s] \n", trimWS(d.String()))
+		t.Errorf("Expected: [%s] \n", trimWS(expectedDoc))
+		t.Errorf(`Unexpected: program.String() wrong. `)
+	}
+}
+
+func TestDirectiveNotRepeatable(t *testing.T) {
+
+	input := `
+directive @access28(role: String!) on FIELD_DEFINITION
+
+type Order28 {
+	total: Float @access28(role: "admin") @access28(role: "finance")
+}
+`
+	var expectedErr [1]string
+	expectedErr[0] = `Duplicate Directive name "@access28" at line: 5, column: 41`
+
+	l := lexer.New(input)
+	p := New(l)
+	_, errs := p.ParseDocument()
+	for _, ex := range expectedErr {
+		found := false
+		for _, err := range errs {
+			if trimWS(err.Error()) == trimWS(ex) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf(`Expected Error = [%q]`, ex)
+		}
+	}
+	for _, got := range errs {
+		found := false
+		for _, exp := range expectedErr {
+			if trimWS(got.Error()) == trimWS(exp) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf(`Unexpected Error = [%q]`, got.Error())
+		}
+	}
+}
+
+func TestDirectiveBuiltin(t *testing.T) {
+
+	input := `
+scalar Url48 @specifiedBy(url: "https://tools.ietf.org/html/rfc3986")
+
+scalar Uri48 @specifiedBy
+
+enum Dir48 {
+  NORTH
+  UP @deprecated(reason: "Use NORTH")
+}
+
+type Obj48 @deprecated {
+  name: String @deprecated
+  age(unit: Int @deprecated(reason: 1)): Int
+}
+
+directive @skip(if: Boolean!) on FIELD
+`
+	var expectedErr []string = []string{
+		`Required argument "url" of directive "@specifiedBy" is missing at line: 4 column: 15`,
+		`Directive "@deprecated" is not registered for OBJECT usage at line: 11 column: 13`,
+		`Required type for argument "reason" is String, got Int at line: 13 column: 29`,
+		`Directive "@skip" is a built-in directive and cannot be redefined at line: 16 column: 12`,
+	}
+
+	l := lexer.New(input)
+	p := New(l).SetDryRun(true)
+	_, errs := p.ParseDocument()
+	for _, ex := range expectedErr {
+		found := false
+		for _, err := range errs {
+			if trimWS(err.Error()) == trimWS(ex) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf(`Expected Error = [%q]`, ex)
+		}
+	}
+	for _, got := range errs {
+		found := false
+		for _, exp := range expectedErr {
+			if trimWS(got.Error()) == trimWS(exp) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf(`Unexpected Error = [%q]`, got.Error())
+		}
+	}
+}
+
+func TestDirectiveNotRepeatableUnresolved(t *testing.T) {
+
+	// the definition of the directive is not cached e.g. a stored statement rechecked on its own
+	input := `type Order28b {
+	total: Float @access28b(role: "admin") @access28b(role: "finance")
+}`
+	expected := `Duplicate Directive name "@access28b" at line: 2 column: 42`
+
+	l := lexer.New(input)
+	p := New(l)
+	stmt := p.ParseStatement()
+	if len(p.perror) > 0 {
+		t.Fatalf(`Unexpected error: %s`, p.perror[0])
+	}
+	var errs []error
+	stmt.CheckDirectiveLocation(&errs)
+	if len(errs) != 1 || trimWS(errs[0].Error()) != trimWS(expected) {
+		t.Errorf(`Expected Error = [%q], got %v`, expected, errs)
+	}
+}
@@ -0,0 +1,64 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rosshpayne/graph-sdl/ast"
+	"github.com/rosshpayne/graph-sdl/lexer"
+)
+
+func TestDryRun(t *testing.T) {
+
+	input := `
+type Dry42 {
+  a: Int
+}
+`
+	l := lexer.New(input)
+	p := New(l).SetDryRun(true)
+	d, errs := p.ParseDocument()
+	for _, err := range errs {
+		if strings.Contains(err.Error(), "PutItem") {
+			t.Errorf(`Unexpected write in a dry run: %s`, err)
+		} else {
+			t.Errorf(`Unexpected error: %s`, err)
+		}
+	}
+	if len(d.Results) != 1 {
+		t.Fatalf(`Expected 1 result, got %v`, d.Results)
+	}
+	if r := d.Results[0]; r.Name != "Dry42" || r.Type != "Object" || r.Outcome != ast.Created || r.Revision != 1 {
+		t.Errorf(`Expected Dry42 to be created as revision 1, got %s %s %s revision %d`, r.Name, r.Type, r.Outcome, r.Revision)
+	}
+	if p.cache.lookup("Dry42") != nil {
+		t.Errorf(`Expected Dry42 to be removed from the cache after a dry run`)
+	}
+}
+
+func TestDryRunStoredTypes(t *testing.T) {
+
+	_, errs := New(lexer.New(`type Dry42A { b: Dry42B } type Dry42B { x: Int }`)).ParseDocument()
+	for _, err := range errs {
+		t.Fatalf(`Unexpected error: %s`, err)
+	}
+	// Dry42A is read from the database during the dry run, and resolved against the Dry42B of the document
+	p := New(lexer.New(`type Dry42B { y: Int } type Dry42C { a: Dry42A }`)).SetDryRun(true)
+	p.cache.CacheClear()
+	_, errs = p.ParseDocument()
+	for _, err := range errs {
+		t.Errorf(`Unexpected error: %s`, err)
+	}
+	for _, name := range []ast.NameValue_{"Dry42A", "Dry42B", "Dry42C"} {
+		if p.cache.lookup(name) != nil {
+			t.Errorf(`Expected %s to be removed from the cache after a dry run`, name)
+		}
+	}
+	a, err := p.cache.FetchAST("Dry42A")
+	if err != nil {
+		t.Fatalf(`Unexpected error: %s`, err)
+	}
+	if b := a.(*ast.Object_).FieldSet[0].Type.AST; b == nil || !strings.Contains(b.String(), "x") {
+		t.Errorf(`Expected Dry42A to reference the stored Dry42B, got %v`, b)
+	}
+}
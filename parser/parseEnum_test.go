@@ -11,7 +11,7 @@ func TestEnumValidx(t *testing.T) {
 
 	input := `
 
-directive @deprecated on ENUM_VALUE | ARGUMENT_DEFINITION
+# @deprecated is a built-in directive
 directive @dep (if : Int) on ENUM_VALUE | ARGUMENT_DEFINITION
 
 enum Direction {
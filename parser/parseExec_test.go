@@ -0,0 +1,110 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/rosshpayne/graph-sdl/lexer"
+)
+
+func TestExecOperations(t *testing.T) {
+
+	input := `
+query HeroNameAndFriends($episode: Episode = JEDI, $withFriends: Boolean! = true, $ids: [ID!]) @opdir {
+  hero(episode: $episode, filter: {ids: $ids, first: 10}) {
+    name
+    heroAlias: name @skip(if: false)
+    __typename
+    type
+    friends @include(if: $withFriends) {
+      name
+      ... on Droid {
+        primaryFunction
+      }
+      ... @include(if: true) { id }
+      ...friendFields @dir
+    }
+  }
+}
+
+{ me { id } }
+
+mutation { like(id: "abc") { count } }
+
+fragment friendFields on Character @fdir {
+  name
+  input: id
+}
+`
+	expectedDoc := `query HeroNameAndFriends($episode: Episode = JEDI $withFriends: Boolean! = true $ids: [ID!]) @opdir {
+	hero(episode:$episode filter:{ids:$ids first:10 } ) {
+	name
+	heroAlias: name @skip(if:false)
+	__typename
+	type
+	friends @include(if:$withFriends) {
+	name
+	... on Droid { primaryFunction }
+	... @include(if:true) { id }
+	...friendFields @dir
+	}
+	}
+	}
+	query { me { id } }
+	mutation { like(id:"abc") { count } }
+	fragment friendFields on Character @fdir { name input: id }`
+
+	l := lexer.New(input)
+	p := New(l)
+	d, errs := p.ParseExecutable()
+	if len(errs) > 0 {
+		t.Errorf("Unexpected, should be 0 errors, got %d", len(errs))
+		for _, v := range errs {
+			t.Errorf(`Unexpected error: %s`, v.Error())
+		}
+	}
+	if compare(d.String(), expectedDoc) {
+		t.Errorf("Got:      [%s] \n", trimWS(d.String()))
+		t.Errorf("Expected: [%s] \n", trimWS(expectedDoc))
+		t.Errorf(`Unexpected: program.String() wrong. `)
+	}
+}
+
+func TestExecErrors(t *testing.T) {
+
+	inputs := []string{`
+query Q($a: Int = $b) {
+  f
+}
+`, `
+{
+  f {
+  }
+}
+`, `
+fragment on on T { a }
+`, `
+subscription S { a(x: ENUMV) }
+type T { a: Int }
+`}
+	expectedErr := []string{
+		`Variable "$b" is not permitted in a constant value at line: 2, column: 20`,
+		`A selection set must contain at least one field or fragment at line: 4, column: 3`,
+		`A fragment cannot be named "on" at line: 2, column: 10`,
+		`Parse aborted. "type" is not an operation or fragment at line: 3, column: 1`,
+	}
+
+	for i, input := range inputs {
+		l := lexer.New(input)
+		p := New(l)
+		_, errs := p.ParseExecutable()
+		if len(errs) != 1 {
+			t.Errorf("Expected 1 error, got %d", len(errs))
+		}
+		for _, got := range errs {
+			if trimWS(got.Error()) != trimWS(expectedErr[i]) {
+				t.Errorf(`Unexpected Error = [%q]`, got.Error())
+				t.Errorf(`Expected Error = [%q]`, expectedErr[i])
+			}
+		}
+	}
+}
@@ -133,3 +133,175 @@ extend input ExampleInputObject {
 		}
 	}
 }
+
+func TestExtendAllKinds(t *testing.T) {
+
+	input := `
+directive @ext29 on SCALAR | UNION | ENUM | INTERFACE | INPUT_OBJECT
+
+scalar Ext29Scalar
+
+type Ext29A {
+  a: Int
+}
+
+type Ext29B {
+  b: Int
+}
+
+union Ext29Union = | Ext29A
+
+enum Ext29Enum {
+  RED
+}
+
+interface Ext29Itf {
+  a: Int
+}
+
+input Ext29Input {
+  a: Int
+}
+
+extend scalar Ext29Scalar @ext29
+
+extend union Ext29Union @ext29
+
+extend union Ext29Union = | Ext29B
+
+extend enum Ext29Enum @ext29
+
+extend enum Ext29Enum {
+  GREEN
+}
+
+extend interface Ext29Itf @ext29
+
+extend input Ext29Input @ext29
+`
+	expectedDoc := `type Ext29A {a:Int}
+	type Ext29B {b:Int}
+	enum Ext29Enum @ext29 {RED GREEN}
+	input Ext29Input @ext29 {a:Int}
+	interface Ext29Itf @ext29 {a:Int}
+	scalar Ext29Scalar @ext29
+	union Ext29Union @ext29 =| Ext29A | Ext29B
+	directive @ext29 on | SCALAR | UNION | ENUM | INTERFACE | INPUT_OBJECT`
+
+	l := lexer.New(input)
+	p := New(l)
+	d, errs := p.ParseDocument()
+	if len(errs) > 0 {
+		t.Errorf("Unexpected, should be 0 errors, got %d", len(errs))
+		for _, v := range errs {
+			t.Errorf(`Unexpected error: %s`, v.Error())
+		}
+	}
+	if compare(d.String(), expectedDoc) {
+		t.Errorf("Got:      [%s] \n", trimWS(d.String()))
+		t.Errorf("Expected: [%s] \n", trimWS(expectedDoc))
+		t.Errorf(`Unexpected: program.String() wrong. `)
+	}
+}
+
+func TestExtendConflicts(t *testing.T) {
+
+	input := `
+enum Ext29bEnum {
+  RED
+}
+
+type Ext29bType {
+  a: Int
+}
+
+extend enum Ext29bEnum {
+  RED
+}
+
+extend type Ext29bType {
+  a: Int
+}
+
+extend scalar Ext29bEnum
+`
+	var expectedErr [5]string
+	expectedErr[0] = `Duplicate Enum Value [RED] at line: 11 column: 3`
+	expectedErr[1] = `extend for type "Ext29bEnum" contains no changes at line: 10, column: 13`
+	expectedErr[2] = `Duplicate Field name "a" at line: 15, column: 3`
+	expectedErr[3] = `extend for type "Ext29bType" contains no changes at line: 14, column: 13`
+	expectedErr[4] = `specified extend type "Ext29bEnum" is not a Scalar type at line: 18, column: 15`
+
+	l := lexer.New(input)
+	p := New(l)
+	_, errs := p.ParseDocument()
+	for _, ex := range expectedErr {
+		found := false
+		for _, err := range errs {
+			if trimWS(err.Error()) == trimWS(ex) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf(`Expected Error = [%q]`, ex)
+		}
+	}
+	for _, got := range errs {
+		found := false
+		for _, exp := range expectedErr {
+			if trimWS(got.Error()) == trimWS(exp) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf(`Unexpected Error = [%q]`, got.Error())
+		}
+	}
+}
+
+func TestExtendFailedNotCached(t *testing.T) {
+
+	input := `
+type Ext30Type {
+  a: Int
+}
+`
+	l := lexer.New(input)
+	p := New(l)
+	_, errs := p.ParseDocument()
+	for _, v := range errs {
+		t.Errorf(`Unexpected error: %s`, v.Error())
+	}
+	//
+	// failed extension must not change the cached type
+	//
+	input = `
+extend type Ext30Type {
+  b: Ext30Unknown
+}
+`
+	l = lexer.New(input)
+	p = New(l)
+	_, errs = p.ParseDocument()
+	if len(errs) == 0 {
+		t.Errorf(`Expected an error for unknown type "Ext30Unknown"`)
+	}
+	input = `
+extend type Ext30Type {
+  c: Int
+}
+`
+	expectedDoc := `type Ext30Type {a : Int c : Int }`
+
+	l = lexer.New(input)
+	p = New(l)
+	d, errs := p.ParseDocument()
+	for _, v := range errs {
+		t.Errorf(`Unexpected error: %s`, v.Error())
+	}
+	if compare(d.String(), expectedDoc) {
+		t.Errorf("Got:      [%s] \n", trimWS(d.String()))
+		t.Errorf("Expected: [%s] \n", trimWS(expectedDoc))
+		t.Errorf(`Unexpected: program.String() wrong. `)
+	}
+}
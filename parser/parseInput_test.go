@@ -184,3 +184,61 @@ func TestInputInvalidName(t *testing.T) {
 		}
 	}
 }
+
+func TestInputCycle(t *testing.T) {
+
+	input := `
+input Cyc46A {
+  b: Cyc46B!
+}
+
+input Cyc46B {
+  a: Cyc46A!
+  self: Cyc46B
+}
+
+input Cyc46List {
+  next: [Cyc46List!]!
+}
+
+input Cyc46Opt {
+  next: Cyc46Opt
+}
+
+input Cyc46Self {
+  me: Cyc46Self!
+}
+`
+	var expectedErr = []string{
+		`Input "Cyc46A" cannot be satisfied, its non-null fields form a cycle: Cyc46A.b at line: 3 column: 3 -> Cyc46B.a at line: 7 column: 3 -> Cyc46A at line: 3 column: 3`,
+		`Input "Cyc46B" cannot be satisfied, its non-null fields form a cycle: Cyc46B.a at line: 7 column: 3 -> Cyc46A.b at line: 3 column: 3 -> Cyc46B at line: 7 column: 3`,
+		`Input "Cyc46Self" cannot be satisfied, its non-null fields form a cycle: Cyc46Self.me at line: 20 column: 3 -> Cyc46Self at line: 20 column: 3`,
+	}
+
+	l := lexer.New(input)
+	p := New(l)
+	p.ClearCache()
+	_, errs := p.ParseDocument()
+	for _, ex := range expectedErr {
+		found := false
+		for _, err := range errs {
+			if trimWS(err.Error()) == trimWS(ex) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf(`Expected Error = [%q]`, ex)
+		}
+	}
+	for _, got := range errs {
+		found := false
+		for _, exp := range expectedErr {
+			if trimWS(got.Error()) == trimWS(exp) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf(`Unexpected Error = [%q]`, got.Error())
+		}
+	}
+}
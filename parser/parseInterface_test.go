@@ -464,7 +464,7 @@ func TestImplements6a(t *testing.T) {
 	`
 
 	var expectedErr [1]string
-	expectedErr[0] = `Type "Business" does not implement interface "NamedEntity", missing "name"`
+	expectedErr[0] = `Field "name" of type "Business" has type "[[String!]]!" which does not implement type "[[String!]!]!" of interface "NamedEntity" at line: 16 column: 4`
 
 	err := db.DeleteType("NamedEntity")
 	if err != nil {
@@ -532,8 +532,11 @@ func TestImplementsInterfaceBadKeyword(t *testing.T) {
 	}
 	`
 
-	var expectedErr [1]string
+	var expectedErr [3]string
 	expectedErr[0] = `Parse aborted. "interfacei" is not a statement keyword at line: 2, column: 2`
+	// parsing resumes at the next definition
+	expectedErr[1] = `"NamedEntity6b" does not exist in document "DefaultDoc" at line: 10 column: 27`
+	expectedErr[2] = `"NamedEntity6b" does not exist in document "DefaultDoc" at line: 15 column: 29`
 
 	l := lexer.New(input)
 	p := New(l)
@@ -736,8 +739,9 @@ type Business2 implements & NamedEntity & ValuedEntity {
 	expectedErr := []string{
 		`Type "Person" does not implement interface "NamedEntity", missing  "XXX"`,
 		`Type "Business" does not implement interface "NamedEntity", missing  "XXX"`,
-		`Type "Business" does not implement interface "ValuedEntity", missing  "size" "length"`,
-		`Type "Business2" does not implement interface "ValuedEntity", missing  "size"`,
+		`Type "Business" does not implement interface "ValuedEntity", missing  "size"`,
+		`Field "length" of type "Business" has type "String" which does not implement type "Float" of interface "ValuedEntity" at line: 22 column: 3`,
+		`Field "size" of type "Business2" has type "String" which does not implement type "[String]" of interface "ValuedEntity" at line: 29 column: 3`,
 	}
 	err := db.DeleteType("NamedEntity")
 	if err != nil {
@@ -819,10 +823,11 @@ type Business2 implements & NamedEntity & ValuedEntity {
   employeeCount: Int
 }
 `
-	var expectedErr [3]string
+	var expectedErr [4]string
 	expectedErr[0] = `Type "Person" does not implement interface "NamedEntity", missing  "XXX"`
 	expectedErr[1] = `Type "Business" does not implement interface "NamedEntity", missing  "XXX"`
-	expectedErr[2] = `Type "Business" does not implement interface "ValuedEntity", missing  "size" "length"`
+	expectedErr[2] = `Type "Business" does not implement interface "ValuedEntity", missing  "size"`
+	expectedErr[3] = `Field "length" of type "Business" has type "String" which does not implement type "Float" of interface "ValuedEntity" at line: 22 column: 3`
 
 	err := db.DeleteType("NamedEntity")
 	if err != nil {
@@ -962,3 +967,216 @@ KILOMETER
 		}
 	}
 }
+
+func TestInterfaceImplementsInterface(t *testing.T) {
+
+	input := `
+interface Entity27 {
+  id: ID!
+}
+
+interface Timestamped27 {
+  createdAt: String
+}
+
+interface Node27 implements Entity27 & Timestamped27 {
+  id: ID!
+  createdAt: String
+}
+
+type Account27 implements Node27 & Entity27 & Timestamped27 {
+  id: ID!
+  createdAt: String
+  owner: String
+}
+`
+	expectedDoc := `type Account27 implements Node27 & Entity27 & Timestamped27 {id:ID! createdAt:String owner:String}
+	interface Entity27 {id:ID!}
+	interface Node27 implements Entity27 & Timestamped27 {id:ID! createdAt:String}
+	interface Timestamped27 {createdAt:String}`
+
+	l := lexer.New(input)
+	p := New(l)
+	d, errs := p.ParseDocument()
+	if len(errs) > 0 {
+		t.Errorf("Unexpected, should be 0 errors, got %d", len(errs))
+		for _, v := range errs {
+			t.Errorf(`Unexpected error: %s`, v.Error())
+		}
+	}
+	if compare(d.String(), expectedDoc) {
+		t.Errorf("Got:      [%s] \n", trimWS(d.String()))
+		t.Errorf("Expected: [%s] \n", trimWS(expectedDoc))
+		t.Errorf(`Unexpected: program.String() wrong. `)
+	}
+}
+
+func TestInterfaceImplementsTransitive(t *testing.T) {
+
+	input := `
+interface Entity27b {
+  id: ID!
+}
+
+interface Node27b implements Entity27b {
+  id: ID!
+  name: String
+}
+
+interface Named27b implements Node27b {
+  id: ID!
+  name: String
+}
+
+type Account27b implements Node27b {
+  id: ID!
+  name: String
+}
+`
+	var expectedErr [2]string
+	expectedErr[0] = `Interface "Named27b" must also implement interface "Entity27b" as it is implemented by interface "Node27b" at line: 11 column: 31`
+	expectedErr[1] = `Type "Account27b" must also implement interface "Entity27b" as it is implemented by interface "Node27b" at line: 16 column: 28`
+
+	l := lexer.New(input)
+	p := New(l)
+	_, errs := p.ParseDocument()
+	for _, ex := range expectedErr {
+		found := false
+		for _, err := range errs {
+			if trimWS(err.Error()) == trimWS(ex) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf(`Expected Error = [%q]`, ex)
+		}
+	}
+	for _, got := range errs {
+		found := false
+		for _, exp := range expectedErr {
+			if trimWS(got.Error()) == trimWS(exp) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf(`Unexpected Error = [%q]`, got.Error())
+		}
+	}
+}
+
+func TestInterfaceImplementsCycle(t *testing.T) {
+
+	input := `
+interface Cycle27A implements Cycle27B {
+  x: Int
+}
+
+interface Cycle27B implements Cycle27A {
+  x: Int
+}
+
+interface Cycle27C implements Cycle27C {
+  x: Int
+}
+`
+	var expectedErr [3]string
+	expectedErr[0] = `Interface "Cycle27A" cannot implement itself, cycle: Cycle27A -> Cycle27B -> Cycle27A at line: 2 column: 11`
+	expectedErr[1] = `Interface "Cycle27B" cannot implement itself, cycle: Cycle27B -> Cycle27A -> Cycle27B at line: 6 column: 11`
+	expectedErr[2] = `Interface "Cycle27C" cannot implement itself at line: 10 column: 31`
+
+	l := lexer.New(input)
+	p := New(l)
+	_, errs := p.ParseDocument()
+	for _, ex := range expectedErr {
+		found := false
+		for _, err := range errs {
+			if trimWS(err.Error()) == trimWS(ex) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf(`Expected Error = [%q]`, ex)
+		}
+	}
+	for _, got := range errs {
+		found := false
+		for _, exp := range expectedErr {
+			if trimWS(got.Error()) == trimWS(exp) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf(`Unexpected Error = [%q]`, got.Error())
+		}
+	}
+}
+
+func TestImplementsCovariant(t *testing.T) {
+
+	input := `
+interface Node45 {
+  id: ID
+}
+
+type Leaf45 implements Node45 {
+  id: ID
+}
+
+union Member45 = Leaf45
+
+interface Owner45 {
+  node: Node45
+  member: Member45
+  nodes: [Node45]
+  size(unit: String, scale: Int!): Int
+}
+
+type Tree45 implements Owner45 {
+  node: Leaf45!
+  member: Leaf45
+  nodes: [Leaf45!]!
+  size(unit: String, scale: Int!, round: Boolean, exact: Boolean = false): Int!
+}
+
+type Bad45 implements Owner45 {
+  node: ID
+  member: Node45
+  nodes: Leaf45
+  size(unit: Int, precision: Int!): Int
+}
+`
+	expectedErr := []string{
+		`Field "node" of type "Bad45" has type "ID" which does not implement type "Node45" of interface "Owner45" at line: 27 column: 3`,
+		`Field "member" of type "Bad45" has type "Node45" which does not implement type "Member45" of interface "Owner45" at line: 28 column: 3`,
+		`Field "nodes" of type "Bad45" has type "Leaf45" which does not implement type "[Node45]" of interface "Owner45" at line: 29 column: 3`,
+		`Argument "unit" of field "size" of type "Bad45" has type "Int", expected "String" as in interface "Owner45" at line: 30 column: 8`,
+		`Field "size" of type "Bad45" is missing argument "scale" of interface "Owner45" at line: 30 column: 3`,
+		`Argument "precision" of field "size" of type "Bad45" must be optional as it is not defined by interface "Owner45" at line: 30 column: 19`,
+	}
+
+	l := lexer.New(input)
+	p := New(l)
+	_, errs := p.ParseDocument()
+	for _, ex := range expectedErr {
+		found := false
+		for _, err := range errs {
+			if trimWS(err.Error()) == trimWS(ex) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf(`Expected Error = [%q]`, ex)
+		}
+	}
+	for _, got := range errs {
+		found := false
+		for _, exp := range expectedErr {
+			if trimWS(got.Error()) == trimWS(exp) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf(`Unexpected Error = [%q]`, got.Error())
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package parser
+
+import (
+	"os"
+	"testing"
+
+	"github.com/rosshpayne/graph-sdl/lexer"
+	"github.com/rosshpayne/graph-sdl/logger"
+)
+
+type logEntry struct {
+	level  logger.Level
+	msg    string
+	fields map[string]interface{}
+}
+
+// recorder is a Logger that keeps the entries it receives.
+type recorder struct {
+	entries []logEntry
+}
+
+func (r *recorder) Log(level logger.Level, msg string, fields ...logger.Field) {
+	e := logEntry{level: level, msg: msg, fields: make(map[string]interface{})}
+	for _, f := range fields {
+		e.fields[f.Key] = f.Value
+	}
+	r.entries = append(r.entries, e)
+}
+
+func TestLogger(t *testing.T) {
+
+	input := `
+type Log41 {
+  a: Int
+}
+`
+	r := &recorder{}
+	l := lexer.New(input)
+	p := New(l).SetLogger(r)
+	defer p.SetLogger(nil) // the cache, ast and db packages share the logger
+	p.ParseDocument("Doc41")
+
+	var parsed, stmt bool
+	for _, e := range r.entries {
+		switch e.msg {
+		case "parse document":
+			parsed = e.level == logger.Info && e.fields[logger.DocumentKey] == "Doc41" && e.fields[logger.PhaseKey] == "parse"
+		case "parsed statement":
+			stmt = e.level == logger.Debug && e.fields[logger.TypeKey] == "Log41"
+		}
+	}
+	if !parsed {
+		t.Errorf(`Expected an Info entry "parse document" for document Doc41`)
+	}
+	if !stmt {
+		t.Errorf(`Expected a Debug entry "parsed statement" for type Log41`)
+	}
+	if _, err := os.Stat("sdlserver.sys.log"); err == nil {
+		t.Errorf(`Unexpected log file sdlserver.sys.log`)
+	}
+}
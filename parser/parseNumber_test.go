@@ -0,0 +1,108 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/rosshpayne/graph-sdl/lexer"
+)
+
+func TestNumberRange(t *testing.T) {
+
+	input := `
+input Num37I {
+  n: Int
+  f: Float
+}
+
+type Num37 {
+  a(x: Int = 2147483647, y: Int = -2147483648): Int
+  b(x: Int = 2147483648): Int
+  c(x: Int = -2147483649): Int
+  d(x: Float = 1.5e400): Int
+  e(x: [Int] = [1, 3000000000]): Int
+  f(x: Num37I = {n: 9999999999, f: 2.5e400}): Int
+  g(x: [Num37I] = [{n: 1}, {n: -9999999999}]): Int
+}
+`
+	var expectedErr [7]string
+	expectedErr[0] = `Int value 2147483648 is outside the 32-bit signed range at line: 9 column: 14`
+	expectedErr[1] = `Int value -2147483649 is outside the 32-bit signed range at line: 10 column: 14`
+	expectedErr[2] = `Float value 1.5e400 is not a finite number at line: 11 column: 16`
+	expectedErr[3] = `Int value 3000000000 is outside the 32-bit signed range at line: 12 column: 20`
+	expectedErr[4] = `Int value 9999999999 is outside the 32-bit signed range at line: 13 column: 21`
+	expectedErr[5] = `Float value 2.5e400 is not a finite number at line: 13 column: 36`
+	expectedErr[6] = `Int value -9999999999 is outside the 32-bit signed range at line: 14 column: 32`
+
+	l := lexer.New(input)
+	p := New(l)
+	_, errs := p.ParseDocument()
+	for _, ex := range expectedErr {
+		found := false
+		for _, err := range errs {
+			if trimWS(err.Error()) == trimWS(ex) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf(`Expected Error = [%q]`, ex)
+		}
+	}
+	for _, got := range errs {
+		found := false
+		for _, exp := range expectedErr {
+			if trimWS(got.Error()) == trimWS(exp) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf(`Unexpected Error = [%q]`, got.Error())
+		}
+	}
+}
+
+func TestNumberSyntax(t *testing.T) {
+
+	input := `
+type Num37a {
+  a(x: Int = 0123): Int
+}
+
+type Num37b {
+  b(x: Int = 12abc): Int
+}
+
+type Num37c {
+  c(x: Float = 1.e5): Int
+}
+`
+	var expectedErr [3]string
+	expectedErr[0] = `Invalid number "0123", leading zero at line: 3 column: 14`
+	expectedErr[1] = `Invalid number "12abc", unexpected character 'a' after the number at line: 7 column: 14`
+	expectedErr[2] = `Invalid number "1.e5", expected a digit after the decimal point at line: 11 column: 16`
+
+	l := lexer.New(input)
+	p := New(l)
+	_, errs := p.ParseDocument()
+	for _, ex := range expectedErr {
+		found := false
+		for _, err := range errs {
+			if trimWS(err.Error()) == trimWS(ex) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf(`Expected Error = [%q]`, ex)
+		}
+	}
+	for _, got := range errs {
+		found := false
+		for _, exp := range expectedErr {
+			if trimWS(got.Error()) == trimWS(exp) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf(`Unexpected Error = [%q]`, got.Error())
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/rosshpayne/graph-sdl/ast"
+	"github.com/rosshpayne/graph-sdl/lexer"
+)
+
+func TestRecoverStatementErrors(t *testing.T) {
+
+	input := `
+typo Rec33A {
+  a: Int
+}
+
+type Rec33B {
+  a Int
+  b: Int
+}
+
+"description of Rec33C"
+type Rec33C {
+  a: Int
+  b: Int @
+
+enum Rec33Enum { A B }
+
+extend fooo Rec33D
+
+scalar Rec33Scalar
+`
+	var expectedErr [5]string
+	expectedErr[0] = `Parse aborted. "typo" is not a statement keyword at line: 2, column: 1`
+	expectedErr[1] = `Expected a colon followed by a GQL-Type, got "Int" at line: 7, column: 5`
+	expectedErr[2] = `Expected name identifer got Enum of "enum" at line: 16, column: 1`
+	expectedErr[3] = `Expected a colon followed by a GQL-Type, got "{" at line: 16, column: 16`
+	expectedErr[4] = `Parse aborted. "fooo" is not a statement keyword at line: 18, column: 8`
+
+	l := lexer.New(input)
+	p := New(l)
+	_, errs := p.ParseDocument()
+	for _, ex := range expectedErr {
+		found := false
+		for _, err := range errs {
+			if trimWS(err.Error()) == trimWS(ex) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf(`Expected Error = [%q]`, ex)
+		}
+	}
+	for _, got := range errs {
+		found := false
+		for _, exp := range expectedErr {
+			if trimWS(got.Error()) == trimWS(exp) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf(`Unexpected Error = [%q]`, got.Error())
+		}
+	}
+}
+
+func TestRecoverErrLimit(t *testing.T) {
+
+	input := `
+type Rec33E1 { a Int }
+
+type Rec33E2 { a Int }
+
+type Rec33E3 { a Int }
+
+type Rec33E4 { a Int }
+`
+	var expectedErr [3]string
+	expectedErr[0] = `Expected a colon followed by a GQL-Type, got "Int" at line: 2, column: 18`
+	expectedErr[1] = `Expected a colon followed by a GQL-Type, got "Int" at line: 4, column: 18`
+	expectedErr[2] = `Expected a colon followed by a GQL-Type, got "Int" at line: 6, column: 18`
+
+	l := lexer.New(input)
+	p := New(l).SetErrLimit(2)
+	_, errs := p.ParseDocument()
+	for _, ex := range expectedErr {
+		found := false
+		for _, err := range errs {
+			if trimWS(err.Error()) == trimWS(ex) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf(`Expected Error = [%q]`, ex)
+		}
+	}
+	for _, got := range errs {
+		found := false
+		for _, exp := range expectedErr {
+			if trimWS(got.Error()) == trimWS(exp) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf(`Unexpected Error = [%q]`, got.Error())
+		}
+	}
+}
+
+func TestRecoverErrLimitSkipsApply(t *testing.T) {
+
+	input := `
+type Rec33OK { a: Int }
+
+type Rec33E1 { a Int }
+
+type Rec33E2 { a Int }
+
+type Rec33E3 { a Int }
+`
+	l := lexer.New(input)
+	p := New(l).SetErrLimit(2)
+	d, _ := p.ParseDocument()
+	if len(d.Results) == 0 {
+		t.Fatalf(`Expected a result for Rec33OK`)
+	}
+	for _, r := range d.Results {
+		if r.Name == "Rec33OK" && r.Outcome != ast.Skipped {
+			t.Errorf(`Expected Rec33OK to be skipped once the error limit is reached, got %s`, r.Outcome)
+		}
+	}
+}
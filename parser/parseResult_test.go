@@ -0,0 +1,104 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/rosshpayne/graph-sdl/ast"
+	"github.com/rosshpayne/graph-sdl/internal/db"
+	"github.com/rosshpayne/graph-sdl/lexer"
+)
+
+func TestApplyResult(t *testing.T) {
+
+	input := `
+type Res43A {
+  b: Res43B
+}
+
+type Res43B {
+  a: Int
+  a: Float
+}
+
+type Res43C {
+  a: Int
+}
+`
+	l := lexer.New(input)
+	p := New(l)
+	d, _ := p.ParseDocument()
+
+	outcomes := make(map[ast.NameValue_]ast.StatementResult)
+	for _, r := range d.Results {
+		outcomes[r.Name] = r
+	}
+	if r := outcomes["Res43A"]; r.Outcome != ast.Skipped {
+		t.Errorf(`Expected Res43A to be skipped, got %s`, r.Outcome)
+	}
+	if r := outcomes["Res43B"]; r.Outcome != ast.Rejected || len(r.Errors) != 1 {
+		t.Errorf(`Expected Res43B to be rejected with 1 error, got %s with %d`, r.Outcome, len(r.Errors))
+	}
+	c := outcomes["Res43C"]
+	if c.Outcome != ast.Created && c.Outcome != ast.Unchanged && c.Outcome != ast.Updated {
+		t.Fatalf(`Expected Res43C to be stored, got %s %v`, c.Outcome, c.Errors)
+	}
+	//
+	// the same statement is not written again
+	//
+	l = lexer.New(`type Res43C { a: Int, }`)
+	p = New(l)
+	d, _ = p.ParseDocument()
+	if len(d.Results) != 1 || d.Results[0].Outcome != ast.Unchanged || d.Results[0].Revision != c.Revision {
+		t.Errorf(`Expected Res43C to be unchanged at revision %d, got %v`, c.Revision, d.Results)
+	}
+}
+
+func TestApplyExtendCreated(t *testing.T) {
+
+	if err := db.DeleteType("Res43Ext"); err != nil {
+		t.Errorf(`Not expected Error =[%q]`, err.Error())
+	}
+	input := `
+type Res43Ext {
+  a: Int
+}
+
+extend type Res43Ext {
+  b: String
+}
+`
+	l := lexer.New(input)
+	p := New(l)
+	d, errs := p.ParseDocument()
+	for _, err := range errs {
+		t.Errorf(`Unexpected error: %s`, err)
+	}
+	if len(d.Results) != 1 || d.Results[0].Outcome != ast.Created {
+		t.Fatalf(`Expected Res43Ext to be created, got %v`, d.Results)
+	}
+	e := p.cache.lookup("Res43Ext")
+	if e == nil {
+		t.Fatalf(`Expected Res43Ext to be cached`)
+	}
+	if !sameStatement(e.data.String(), d.StatementsMap["Res43Ext"].String()) {
+		t.Errorf(`Expected the cached Res43Ext to be extended, got %s`, e.data)
+	}
+}
+
+func TestSameStatement(t *testing.T) {
+	tests := []struct {
+		a, b string
+		same bool
+	}{
+		{`type A {a:Int b:[String!]}`, "type A {\n  a: Int,\n  b: [String!]\n}", true},
+		{`type A {a:Int}`, `type A {a:Float}`, false},
+		{`"a  b" type A {a:Int}`, `"a b" type A {a:Int}`, false},
+		{`type A {a:Int #x
+}`, `type A {a:Int}`, false},
+	}
+	for i, tt := range tests {
+		if got := sameStatement(tt.a, tt.b); got != tt.same {
+			t.Errorf(`tests[%d] - expected %v, got %v`, i, tt.same, got)
+		}
+	}
+}
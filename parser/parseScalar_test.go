@@ -2,9 +2,12 @@ package parser
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/rosshpayne/graph-sdl/ast"
 	"github.com/rosshpayne/graph-sdl/internal/db"
 	"github.com/rosshpayne/graph-sdl/lexer"
 )
@@ -115,7 +118,7 @@ func TestScalarArgumentInvalidValue(t *testing.T) {
 `
 
 	var expectedErr [1]string
-	expectedErr[0] = `Error in parsing of Time value `
+	expectedErr[0] = `Invalid Time value "Feb 3, 2013 at 7:784pm (PST)", expected "Jan 2, 2006 at 3:04pm (MST)", "2006-Jan-02" or an RFC 3339 date-time at line: 3 column: 23`
 
 	l := lexer.New(input)
 	p := New(l)
@@ -531,3 +534,146 @@ func TestScalarNestedTypeCheck2(t *testing.T) {
 		}
 	}
 }
+
+func TestScalarRegistry(t *testing.T) {
+
+	err := ast.RegisterScalar("Even50", ast.ScalarFuncs{
+		Parse: func(v ast.InputValueProvider) (interface{}, error) {
+			i, ok := v.(ast.Int_)
+			if !ok {
+				return nil, fmt.Errorf("Even50 value must be an Int")
+			}
+			return strconv.Atoi(string(i))
+		},
+		Validate: func(v interface{}) error {
+			if v.(int)%2 != 0 {
+				return fmt.Errorf("Even50 value %d is odd", v)
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := ast.UnregisterScalar("Even50"); err != nil {
+			t.Error(err)
+		}
+	})
+	if err := ast.UnregisterScalar("DateTime"); err == nil {
+		t.Errorf(`Expected an error unregistering the built-in scalar DateTime`)
+	}
+	if err := ast.RegisterScalar("Int", ast.ScalarFuncs{Parse: func(v ast.InputValueProvider) (interface{}, error) { return v, nil }}); err == nil {
+		t.Errorf(`Expected an error registering the built-in scalar Int`)
+	}
+
+	input := `
+scalar DateTime
+scalar Date
+scalar JSON
+scalar URL
+scalar UUID
+scalar BigInt
+scalar Email
+scalar Even50
+scalar Any50
+
+directive @at50(when: DateTime) on FIELD_DEFINITION
+
+input When50 {
+  when: DateTime
+  ids: [UUID]
+}
+
+type Scalars50 {
+  a(v: DateTime = "2020-01-02T03:04:05+10:00"): Int @at50(when: "2020-01-02")
+  b(v: Date = "2020-01-02", w: Date = "02/01/2020"): Int
+  c(v: JSON = "{ \"x\": [1, 2] }", w: JSON = "{x}"): Int
+  d(v: URL = "https://example.com/a", w: URL = "example"): Int
+  e(v: UUID = "123e4567-E89B-12d3-a456-426614174000", w: UUID = "123"): Int
+  f(v: BigInt = 123456789012345678901234567890, w: BigInt = 1.5): Int
+  g(v: Email = "a@example.com", w: Email = "A <a@example.com>"): Int
+  h(v: Even50 = 2, w: Even50 = 3): Int
+  i(v: Any50 = "anything"): Int
+  j(v: [DateTime] = ["2020-01-01T00:00:00Z"], w: [DateTime] = ["2020-01-01T00:00:00Z", "yesterday"]): Int
+  k(v: [UUID] = ["AAAAAAAA-0000-0000-0000-000000000000"]): Int
+  l(v: When50 = {when: "2020-01-01T00:00:00Z", ids: ["BBBBBBBB-0000-0000-0000-000000000000"]}): Int
+  m(v: When50 = {when: "soon", ids: ["123"]}): Int
+}
+`
+	expectedErr := []string{
+		`Invalid DateTime value "2020-01-02", expected an RFC 3339 date-time at line: 20 column: 65`,
+		`Invalid Date value "02/01/2020", expected YYYY-MM-DD at line: 21 column: 39`,
+		`Invalid JSON value: invalid character 'x' looking for beginning of object key string at line: 22 column: 46`,
+		`Invalid URL value "example", expected an absolute URL at line: 23 column: 48`,
+		`Invalid UUID value "123" at line: 24 column: 65`,
+		`BigInt value must be an integer or a string at line: 25 column: 61`,
+		`Invalid Email value "A <a@example.com>" at line: 26 column: 44`,
+		`Even50 value 3 is odd at line: 27 column: 32`,
+		`Invalid DateTime value "yesterday", expected an RFC 3339 date-time at line: 29 column: 88`,
+		`Invalid DateTime value "soon", expected an RFC 3339 date-time at line: 32 column: 24`,
+		`Invalid UUID value "123" at line: 32 column: 38`,
+	}
+
+	l := lexer.New(input)
+	p := New(l).SetDryRun(true)
+	d, errs := p.ParseDocument()
+	for _, ex := range expectedErr {
+		found := false
+		for _, err := range errs {
+			if trimWS(err.Error()) == trimWS(ex) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf(`Expected Error = [%q]`, ex)
+		}
+	}
+	for _, got := range errs {
+		found := false
+		for _, exp := range expectedErr {
+			if trimWS(got.Error()) == trimWS(exp) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf(`Unexpected Error = [%q]`, got.Error())
+		}
+	}
+	// coerced values are printed in their serialized form
+	for _, ex := range []string{`"2020-01-02T03:04:05+10:00"`, `"{\"x\":[1,2]}"`, `"123e4567-e89b-12d3-a456-426614174000"`, `123456789012345678901234567890`, `"anything"`, `"aaaaaaaa-0000-0000-0000-000000000000"`, `"bbbbbbbb-0000-0000-0000-000000000000"`} {
+		if !strings.Contains(d.StatementsMap["Scalars50"].String(), ex) {
+			t.Errorf(`Expected %s in %s`, ex, d.StatementsMap["Scalars50"])
+		}
+	}
+}
+
+func TestScalarCoerceTwice(t *testing.T) {
+
+	input := `
+scalar DateTime
+scalar UUID
+
+input Twice50 {
+  at: DateTime = "2020-01-02T03:04:05Z"
+}
+
+type Scalars50b {
+  a(v: DateTime = "2020-01-02T03:04:05Z", w: [UUID] = ["AAAAAAAA-0000-0000-0000-000000000000"], x: Twice50 = {at: "2020-01-02T03:04:05Z"}): Int
+}
+`
+	l := lexer.New(input)
+	p := New(l).SetDryRun(true)
+	d, errs := p.ParseDocument()
+	for _, err := range errs {
+		t.Errorf(`Unexpected Error = [%q]`, err.Error())
+	}
+	// a coerced default is accepted when validated again e.g. once its type is extended or a dependent is rechecked
+	for _, name := range []ast.NameValue_{"Twice50", "Scalars50b"} {
+		var errs []error
+		d.StatementsMap[name].CheckInputValueType(&errs)
+		for _, err := range errs {
+			t.Errorf(`Unexpected Error validating %s again = [%q]`, name, err.Error())
+		}
+	}
+}
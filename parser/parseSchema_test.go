@@ -311,7 +311,10 @@ schema {
 		mutation:Mutation
 		subscription:Subscription
 	}`
-	var expectedErr []string
+	expectedErr := []string{
+		`"Mutation" does not exist in document "DefaultDoc" at line: 4 column: 15`,
+		`"Subscription" does not exist in document "DefaultDoc" at line: 5 column: 19`,
+	}
 
 	l := lexer.New(input)
 	p := New(l)
@@ -529,3 +532,115 @@ picture(size : Int =12918@NME(if:"""abc""") size2 : Boolean  @NME34(ifx:false) @
 	}
 
 }
+
+func TestSchemaRootValidation(t *testing.T) {
+
+	input := `
+type Sch47Q {
+  a: Int
+}
+
+enum Sch47E { X Y }
+
+schema {
+  query: Sch47Q
+  mutation: Sch47Q
+  subscription: Sch47E
+}
+
+schema {
+  query: Sch47Q
+}
+`
+	expectedErr := []string{
+		`Schema mutation root type "Sch47Q" is already the query root type. Root types must be distinct at line: 10 column: 13`,
+		`Schema subscription root type "Sch47E" must be an Object type at line: 11 column: 17`,
+		`Schema is already defined in the document. Use "extend schema" to add to it at line: 14 column: 1`,
+	}
+
+	l := lexer.New(input)
+	p := New(l).SetDryRun(true)
+	_, errs := p.ParseDocument()
+	for _, ex := range expectedErr {
+		found := false
+		for _, err := range errs {
+			if trimWS(err.Error()) == trimWS(ex) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf(`Expected Error = [%q]`, ex)
+		}
+	}
+	for _, got := range errs {
+		found := false
+		for _, exp := range expectedErr {
+			if trimWS(got.Error()) == trimWS(exp) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf(`Unexpected Error = [%q]`, got.Error())
+		}
+	}
+}
+
+func TestSchemaNoQuery(t *testing.T) {
+
+	input := `
+type Sch47M {
+  a: Int
+}
+
+directive @sch47 on SCHEMA
+
+schema @sch47 {
+  mutation: Sch47M
+}
+
+extend schema {
+  subscription: Sch47M
+}
+`
+	expectedErr := []string{
+		`Schema must define a query root operation type at line: 8 column: 1`,
+		`Schema subscription root type "Sch47M" is already the mutation root type. Root types must be distinct at line: 13 column: 17`,
+	}
+	expectedDoc := `directive @sch47 on | SCHEMA
+	type Sch47M {a: Int}
+	schema @sch47 {
+		mutation: Sch47M
+		subscription: Sch47M
+	}`
+
+	l := lexer.New(input)
+	p := New(l).SetDryRun(true)
+	d, errs := p.ParseDocument()
+	for _, ex := range expectedErr {
+		found := false
+		for _, err := range errs {
+			if trimWS(err.Error()) == trimWS(ex) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf(`Expected Error = [%q]`, ex)
+		}
+	}
+	for _, got := range errs {
+		found := false
+		for _, exp := range expectedErr {
+			if trimWS(got.Error()) == trimWS(exp) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf(`Unexpected Error = [%q]`, got.Error())
+		}
+	}
+	if compare(d.String(), expectedDoc) {
+		fmt.Println("Got:  " + trimWS(d.String()))
+		fmt.Println("Exp:  " + trimWS(expectedDoc))
+		t.Errorf(`*************  program.String() wrong.`)
+	}
+}
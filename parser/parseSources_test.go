@@ -0,0 +1,56 @@
+package parser
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rosshpayne/graph-sdl/ast"
+	"github.com/rosshpayne/graph-sdl/lexer"
+)
+
+func TestSources(t *testing.T) {
+
+	a := `
+type Src35A {
+  b: Src35B
+}
+`
+	b := `
+type Src35B {
+  a: Int
+  a: Float
+}
+`
+	var expectedErr [1]string
+	expectedErr[0] = `Duplicate Field name "a" at line: 4 column: 3 in b.graphql`
+
+	l := lexer.NewSources(lexer.Source{Name: "a.graphql", Input: a}, lexer.Source{Name: "b.graphql", Input: b})
+	p := New(l)
+	_, errs := p.ParseDocument()
+	for _, ex := range expectedErr {
+		found := false
+		for _, err := range errs {
+			if trimWS(err.Error()) == trimWS(ex) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf(`Expected Error = [%q]`, ex)
+		}
+	}
+	for _, got := range errs {
+		found := false
+		for _, exp := range expectedErr {
+			if trimWS(got.Error()) == trimWS(exp) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf(`Unexpected Error = [%q]`, got.Error())
+		}
+		var d *ast.Diagnostic
+		if errors.As(got, &d) && d.Span.File != "b.graphql" {
+			t.Errorf(`Expected file "b.graphql" in the span of [%q], got "%s"`, got.Error(), d.Span.File)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package parser
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rosshpayne/graph-sdl/ast"
+	"github.com/rosshpayne/graph-sdl/lexer"
+)
+
+func TestSpans(t *testing.T) {
+
+	input := `
+"desc"
+type Span39 {
+  a(x: [Int!] = [1, 2]): [String!]!
+}
+`
+	l := lexer.New(input)
+	p := New(l)
+	d, errs := p.ParseDocument()
+	for _, v := range errs {
+		t.Errorf(`Unexpected error: %s`, v.Error())
+	}
+	obj, ok := d.StatementsMap["Span39"].(*ast.Object_)
+	if !ok {
+		t.Fatalf(`Expected Object Span39`)
+	}
+	fld := obj.FieldSet[0]
+	arg := fld.ArgumentDefs[0]
+
+	tests := []struct {
+		node     string
+		got      ast.Span
+		expected ast.Span
+	}{
+		{"statement", obj.Span, ast.Span{Start: ast.Pos{Line: 2, Column: 1, Offset: 1}, End: ast.Pos{Line: 5, Column: 2, Offset: 59}}},
+		{"name", ast.SpanOf(obj.Name_.Loc), ast.Span{Start: ast.Pos{Line: 3, Column: 6, Offset: 13}, End: ast.Pos{Line: 3, Column: 12, Offset: 19}}},
+		{"field", fld.Span, ast.Span{Start: ast.Pos{Line: 4, Column: 3, Offset: 24}, End: ast.Pos{Line: 4, Column: 36, Offset: 57}}},
+		{"argument", arg.Span, ast.Span{Start: ast.Pos{Line: 4, Column: 5, Offset: 26}, End: ast.Pos{Line: 4, Column: 23, Offset: 44}}},
+		{"field type", fld.Type.Span, ast.Span{Start: ast.Pos{Line: 4, Column: 26, Offset: 47}, End: ast.Pos{Line: 4, Column: 36, Offset: 57}}},
+		{"argument type", arg.Type.Span, ast.Span{Start: ast.Pos{Line: 4, Column: 8, Offset: 29}, End: ast.Pos{Line: 4, Column: 14, Offset: 35}}},
+		{"default value", arg.DefaultVal.Span, ast.Span{Start: ast.Pos{Line: 4, Column: 17, Offset: 38}, End: ast.Pos{Line: 4, Column: 23, Offset: 44}}},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.expected {
+			t.Errorf(`Expected %s span %v-%v, got %v-%v`, tt.node, tt.expected.Start, tt.expected.End, tt.got.Start, tt.got.End)
+		}
+	}
+}
+
+func TestDiagnosticSpan(t *testing.T) {
+
+	input := `
+type Span39a {
+  name: Int
+  name: Int
+}
+`
+	l := lexer.New(input)
+	p := New(l)
+	_, errs := p.ParseDocument()
+	var found bool
+	for _, err := range errs {
+		var d *ast.Diagnostic
+		if errors.As(err, &d) && d.Code == ast.CodeDuplicate {
+			found = true
+			expected := ast.Span{Start: ast.Pos{Line: 4, Column: 3, Offset: 30}, End: ast.Pos{Line: 4, Column: 7, Offset: 34}}
+			if d.Span != expected {
+				t.Errorf(`Expected span %v-%v, got %v-%v`, expected.Start, expected.End, d.Span.Start, d.Span.End)
+			}
+		}
+	}
+	if !found {
+		t.Errorf(`Expected a duplicate field diagnostic`)
+	}
+}
@@ -0,0 +1,53 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/rosshpayne/graph-sdl/lexer"
+)
+
+func TestStringValues(t *testing.T) {
+
+	input := `
+"""
+  Quoted "value" with a \""" delimiter
+    and an indented line
+"""
+type Str36 {
+  "tab\tand é and \\ backslash"
+  a(x: String = "line\nbreak \"quoted\""): Int
+  b(x: String = """  leading space"""): Int
+  c(x: String = """ends with "quote"
+"""): Int
+}
+`
+	expectedDoc := `"""Quoted "value" with a \""" delimiter
+  and an indented line"""
+type Str36 {"tab\tand é and \\ backslash" a(x: String = "line\nbreak \"quoted\""): Int
+b(x: String = """  leading space"""): Int
+c(x: String = """ends with "quote"
+"""): Int}`
+
+	l := lexer.New(input)
+	p := New(l)
+	d, errs := p.ParseDocument()
+	for _, v := range errs {
+		t.Errorf(`Unexpected error: %s`, v.Error())
+	}
+	if compare(d.String(), expectedDoc) {
+		t.Errorf("Got:      [%s] \n", trimWS(d.String()))
+		t.Errorf("Expected: [%s] \n", trimWS(expectedDoc))
+		t.Errorf(`Unexpected: program.String() wrong. `)
+	}
+	// the printed document reads back as the same document
+	l = lexer.New(d.String())
+	p = New(l)
+	d2, errs := p.ParseDocument()
+	for _, v := range errs {
+		t.Errorf(`Unexpected error: %s`, v.Error())
+	}
+	if d2.String() != d.String() {
+		t.Errorf("Got:      [%s] \n", d2.String())
+		t.Errorf("Expected: [%s] \n", d.String())
+	}
+}
@@ -3,8 +3,6 @@ package parser
 import (
 	"errors"
 	"fmt"
-	"log"
-	"os"
 	"strconv"
 	"strings"
 
@@ -12,14 +10,14 @@ import (
 	"github.com/rosshpayne/graph-sdl/internal/db"
 	"github.com/rosshpayne/graph-sdl/internal/token"
 	"github.com/rosshpayne/graph-sdl/lexer"
+	"github.com/rosshpayne/graph-sdl/logger"
 )
 
 const (
-	cErrLimit  = 8 // how many parse errors are permitted before processing stops
-	Executable = 'E'
-	TypeSystem = 'T'
-	defaultDoc = "DefaultDoc"
-	logrFlags  = log.LstdFlags | log.Lshortfile
+	DefaultErrLimit = 10 // how many errors are permitted before processing stops, see SetErrLimit
+	Executable      = 'E'
+	TypeSystem      = 'T'
+	defaultDoc      = "DefaultDoc"
 )
 
 // Error exit codes
@@ -58,6 +56,10 @@ const (
 	parseType_
 	parseDefaultVal_
 	parseInputValue__
+	//
+	parseVariableDefs_
+	parseSelectionSet_
+	parseTypeCondition_
 )
 
 type (
@@ -67,17 +69,40 @@ type (
 		l *lexer.Lexer
 
 		extend bool
+		// extended holds the extensions parsed in the current document. Each is a copy of the cached type
+		// that replaces the cached type only once it validates.
+		extended map[ast.NameValue_]ast.GQLTypeProvider
+
+		exec     bool // parsing an executable document, which permits variables
+		constant bool // parsing a value that cannot reference a variable e.g. a variable's default value
+
+		dryRun   bool                                 // validate the document without persisting it, see SetDryRun
+		dryCache map[string]*entry                    // cache entries when a dry run started, which the cache is restored to
+		refs     map[ast.NameValue_]ast.UnresolvedMap // types referenced by each statement of the document
+		stored   map[ast.NameValue_]*db.TypeRow       // stored rows read for the document, nil when none is stored
+		// validated is set once the document passes every validation phase. Until then no statement is applied.
+		validated bool
+		//
+		blockDependents   bool // a change that invalidates a stored dependent is rejected, see SetBlockOnDependents
+		deprecateVersions int  // revisions a member must be deprecated for before removal, see SetDeprecationPolicy
+		deprecateDays     int  // days a member must be deprecated for before removal, see SetDeprecationPolicy
 
 		cache *Cache_
 
-		logr *log.Logger
-		logf *os.File
+		logr logger.Logger
 
 		abort     bool
+		errLimit  int // zero or less for no limit
 		stmtType  string
 		state     stateT
 		curToken  *token.Token
 		peekToken *token.Token
+		prevLoc   token.Pos // location of the token before curToken
+		prevEnd   token.Pos // end of the token before curToken
+		//
+		comments []*token.Token      // comments, in lexer comment mode, not yet attached to a definition
+		lastNode ast.CommentAssigner // most recent field, argument or enum value definition
+		lastLoc  token.Pos           // where lastNode starts
 
 		parseFns map[token.TokenType]parseFn
 		perror   []error
@@ -118,7 +143,10 @@ var (
 
 func New(l *lexer.Lexer) *Parser {
 	p := &Parser{
-		l: l,
+		l:               l,
+		errLimit:        DefaultErrLimit,
+		logr:            logger.Nop,
+		blockDependents: true,
 	}
 	// assigns "shared" cache across multiple parsers or parser uses goroutines for processing. None of which is currently employeed.
 	//  sharing cache was an exercise in making the cache concurrency safe rather than an actual design necessarity.
@@ -150,19 +178,28 @@ func (p *Parser) Getperror() []error {
 }
 
 func (p *Parser) Loc() *ast.Loc_ {
-	loc := p.curToken.Loc
-	return &ast.Loc_{loc.Line, loc.Col}
+	loc, end := p.curToken.Loc, p.curToken.End
+	return &ast.Loc_{Line: loc.Line, Column: loc.Col, Offset: loc.Offset, File: loc.File, End: ast.Pos{Line: end.Line, Column: end.Col, Offset: end.Offset}}
+}
+
+// spanFrom returns the span from start to the end of the last token read over i.e. the token before curToken.
+func (p *Parser) spanFrom(start token.Pos) ast.Span {
+	return ast.Span{
+		File:  start.File,
+		Start: ast.Pos{Line: start.Line, Column: start.Col, Offset: start.Offset},
+		End:   ast.Pos{Line: p.prevEnd.Line, Column: p.prevEnd.Col, Offset: p.prevEnd.Offset},
+	}
 }
 
 // func (p *Parser) addEntry() {
 // 	p.cache.CacheClear()
 // }
 func (p *Parser) printToken(s ...string) {
+	fields := []logger.Field{logger.Any("current", p.curToken.Literal), logger.Any("currentType", p.curToken.Type), logger.Any("next", p.peekToken.Literal), logger.Any("nextType", p.peekToken.Type)}
 	if len(s) > 0 {
-		fmt.Printf("** Current Token: [%s] %s %s %s %v %s %s [%s]\n", s[0], p.curToken.Type, p.curToken.Literal, p.curToken.Cat, p.curToken.IsScalarType, "Next Token:  ", p.peekToken.Type, p.peekToken.Literal)
-	} else {
-		fmt.Println("** Current Token: ", p.curToken.Type, p.curToken.Literal, p.curToken.Cat, "Next Token:  ", p.peekToken.Type, p.peekToken.Literal)
+		fields = append(fields, logger.Any("at", s[0]))
 	}
+	p.logr.Log(logger.Debug, "token", fields...)
 }
 
 // containersErr accepts a "validation" method value and the number of errors that can be generated in its call to abort the process,
@@ -190,21 +227,66 @@ func LoadASTcache(c *Cache_) {
 	}
 }
 
+// SetLogger sets the logger that receives the progress of ParseDocument and ValidateExecutable. The parser is silent by default.
+// As the type cache is shared by all parsers, so is the logger of the cache, and of the validation and database
+// access the parser drives, which log to the logger set most recently. A nil logger silences logging.
+func (p *Parser) SetLogger(l logger.Logger) *Parser {
+	if l == nil {
+		l = logger.Nop
+	}
+	p.logr = l
+	p.cache.SetLogger(l)
+	ast.SetLogger(l)
+	db.SetLogger(l)
+	return p
+}
+
+// SetDryRun sets validate-only mode. ParseDocument then parses and validates the document against the stored types
+// as usual, and reports in Document.Results what would be created or updated, but persists nothing.
+func (p *Parser) SetDryRun(on bool) *Parser {
+	p.dryRun = on
+	return p
+}
+
+// SetBlockOnDependents sets whether a change to a stored type that invalidates a stored type depending on it, e.g. an object
+// implementing a changed interface, is rejected (the default) or is applied with the failures reported as warnings.
+func (p *Parser) SetBlockOnDependents(block bool) *Parser {
+	p.blockDependents = block
+	return p
+}
+
+// SetDeprecationPolicy blocks a change that removes a field, argument, input field or enum value of a stored type unless the
+// member has been deprecated for at least versions revisions of the type and for at least days days. A zero value
+// disables that part of the policy. The policy is disabled by default.
+func (p *Parser) SetDeprecationPolicy(versions, days int) *Parser {
+	p.deprecateVersions, p.deprecateDays = versions, days
+	return p
+}
+
+// SetErrLimit sets the number of errors permitted before processing stops. A limit of zero or less removes the limit.
+// Once the limit is reached the document is not fully validated, so none of its statements are applied: each
+// is reported as rejected, or as skipped when it has no errors.
+func (p *Parser) SetErrLimit(n int) *Parser {
+	p.errLimit = n
+	return p
+}
+
+func (p *Parser) errLimitReached(n int) bool {
+	return p.errLimit > 0 && n > p.errLimit
+}
+
 func (p *Parser) hasError() bool {
 
-	if len(p.perror) > 10 || p.abort {
+	if p.errLimitReached(len(p.perror)) || p.abort {
 		return true
 	}
 	return false
 }
 
-// addErr appends to error slice held in parser.
+// addErr appends a syntax error, located at the current token, to error slice held in parser.
 func (p *Parser) addErr(s string, xCode ...int) error {
 
-	if strings.Index(s, " at line: ") == -1 {
-		s += fmt.Sprintf(" at line: %d, column: %d", p.curToken.Loc.Line, p.curToken.Loc.Col)
-	}
-	e := errors.New(s)
+	e := ast.Diagf(ast.CodeSyntax, p.Loc(), "%s", s)
 	p.perror = append(p.perror, e)
 	if len(xCode) > 0 {
 		p.abort = true
@@ -212,6 +294,14 @@ func (p *Parser) addErr(s string, xCode ...int) error {
 	return e
 }
 
+// addDiag appends a Diagnostic to error slice held in parser. Format and arguments are as for fmt.Errorf.
+func (p *Parser) addDiag(code ast.ErrCode, loc *ast.Loc_, format string, a ...interface{}) *ast.Diagnostic {
+
+	e := ast.Diagf(code, loc, format, a...)
+	p.perror = append(p.perror, e)
+	return e
+}
+
 // addErr2 appends to error slice held in parser.
 func (p *Parser) addErr2(e error) error {
 
@@ -219,21 +309,42 @@ func (p *Parser) addErr2(e error) error {
 	return e
 }
 
+// setOwner records the statement that owns each diagnostic.
+func setOwner(name ast.NameValue_, errs []error) {
+	for _, e := range errs {
+		var d *ast.Diagnostic
+		if errors.As(e, &d) && len(d.TypeName) == 0 {
+			d.TypeName = name
+		}
+	}
+}
+
 func (p *Parser) registerFn(tokenType token.TokenType, fn parseFn) {
 	p.parseFns[tokenType] = fn
 }
 
 func (p *Parser) nextToken(s ...string) {
+	if p.curToken != nil {
+		p.prevLoc, p.prevEnd = p.curToken.Loc, p.curToken.End
+	}
 	p.curToken = p.peekToken
 
 	p.peekToken = p.l.NextToken() // get another token from lexer:    [,+,(,99,Identifier,keyword,EOF etc.
+	for p.peekToken.Type == token.COMMENT {
+		p.addComment(p.peekToken)
+		p.peekToken = p.l.NextToken()
+	}
 	//fmt.Println("nextToken: ", p.peekToken.Type, p.peekToken.Literal)
 	if len(s) > 0 {
-		fmt.Printf("** Current Token: [%s] %s %s %s %s %s %s\n", s[0], p.curToken.Type, p.curToken.Literal, p.curToken.Cat, "Next Token:  ", p.peekToken.Type, p.peekToken.Literal)
+		p.printToken(s...)
 	}
 	if p.curToken != nil {
 		if p.curToken.Illegal {
-			p.addErr(fmt.Sprintf("Illegal %s token, [%s]", p.curToken.Type, p.curToken.Literal))
+			if len(p.curToken.Err) > 0 {
+				p.addErr(p.curToken.Err)
+			} else {
+				p.addErr(fmt.Sprintf("Illegal %s token, [%s]", p.curToken.Type, p.curToken.Literal))
+			}
 		}
 		// if $variable present then mark the identier as a VALUE
 		if p.curToken.Literal == token.DOLLAR {
@@ -242,22 +353,193 @@ func (p *Parser) nextToken(s ...string) {
 	}
 }
 
-//
-func openLogFile() *os.File {
-	logf, err := os.OpenFile("sdlserver.sys.log", os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0755)
+func (p *Parser) ClearCache() {}
+
+// cacheStatement adds a statement of the document to the cache. In a dry run the cache is restored once the
+// document is validated, see ParseDocument.
+func (p *Parser) cacheStatement(name ast.NameValue_, stmt ast.GQLTypeProvider) {
+	p.cache.addEntry(name, stmt)
+}
+
+// apply persists the statements of the document and returns the outcome of each. A statement is not written when it has
+// errors, references a statement of the document that is not written because of errors, is unchanged from the
+// stored statement or the document did not complete validation e.g. the error limit was reached. In a dry run nothing is written.
+func (p *Parser) apply(api *ast.Document, document string) []ast.StatementResult {
+	var (
+		results []ast.StatementResult
+		skipped = p.dependents(api)
+		applied = make(map[ast.NameValue_]bool)
+	)
+	for _, v := range api.Statements {
+		name := v.TypeName()
+		if api.StatementsMap[name] != v || applied[name] {
+			// a statement replaced by a later statement of the same name
+			continue
+		}
+		applied[name] = true
+		r := ast.StatementResult{Name: name, Type: v.Type()}
+		stored, err := p.storedRow(name)
+		switch {
+		case len(api.ErrorMap[name]) > 0:
+			r.Outcome, r.Errors = ast.Rejected, api.ErrorMap[name]
+		case skipped[name], !p.validated:
+			// a dependent of a rejected statement, or validation stopped at the error limit before the statement was fully checked
+			r.Outcome = ast.Skipped
+		case err != nil:
+			r.Outcome, r.Errors = ast.Rejected, []error{p.dbError(err, document, name)}
+			stored = nil
+		case stored != nil && sameStatement(stored.Stmt, v.String()):
+			r.Outcome = ast.Unchanged
+		default:
+			r.Outcome = ast.Created
+			if stored != nil {
+				r.Outcome = ast.Updated
+			}
+			row := db.NewRow(name.String(), v, stored)
+			if !p.dryRun {
+				p.logr.Log(logger.Debug, "persist type", logger.Document(document), logger.Type(name.String()), logger.Phase("persist"))
+				if err := db.Persist(row, v); err != nil {
+					r.Outcome, r.Errors = ast.Rejected, []error{p.dbError(err, document, name)}
+					row = stored
+				}
+			}
+			stored = row
+		}
+		if r.Outcome == ast.Unchanged && !p.dryRun && stored.RecordDeprecations(v) {
+			// a row written before deprecations were recorded. A failure is retried when the statement is next applied.
+			if err := db.Persist(stored, v); err != nil {
+				p.logr.Log(logger.Warn, err.Error(), logger.Document(document), logger.Type(name.String()), logger.Phase("persist"))
+			}
+		}
+		if _, ok := p.extended[name]; ok && !p.dryRun && r.Outcome != ast.Rejected && r.Outcome != ast.Skipped {
+			// the extended copy replaces the cached type, including one defined earlier in the document
+			p.cache.addEntry(name, v)
+		}
+		if stored != nil {
+			r.Revision, r.Inserted, r.Updated = stored.Revision(), stored.Inserted(), stored.Updated()
+		}
+		if r.Outcome != ast.Rejected && r.Outcome != ast.Skipped {
+			api.Deprecations = append(api.Deprecations, deprecations(v, stored)...)
+		}
+		p.logr.Log(logger.Info, r.Outcome.String(), logger.Document(document), logger.Type(name.String()), logger.Phase("persist"), logger.Any("revision", r.Revision))
+		results = append(results, r)
+	}
+	return results
+}
+
+// deprecations returns the deprecated members of the statement, with the revision of row each was deprecated in.
+func deprecations(stmt ast.GQLTypeProvider, row *db.TypeRow) []ast.Deprecation {
+	d := ast.Deprecations(stmt)
+	if row == nil {
+		return d
+	}
+	for i := range d {
+		if dp, ok := row.Dp[d[i].Path]; ok {
+			d[i].Revision, d[i].Since = dp.R, dp.Since()
+		}
+	}
+	return d
+}
+
+// StoredDeprecations returns the deprecated members of the statements stored in the named document (default document
+// if not specified), with the revision each was deprecated in.
+func (p *Parser) StoredDeprecations(doc ...string) ([]ast.Deprecation, error) {
+	document := defaultDoc
+	if len(doc) > 0 {
+		document = doc[0]
+	}
+	db.SetDefaultDoc(defaultDoc)
+	db.SetDocument(document)
+	p.stored = make(map[ast.NameValue_]*db.TypeRow)
+
+	names, err := db.DocumentTypes()
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
-	return logf
+	var d []ast.Deprecation
+	for _, n := range names {
+		stored, err := p.storedRow(ast.NameValue_(n))
+		if err != nil {
+			return nil, err
+		}
+		if stored == nil {
+			continue
+		}
+		p2 := New(lexer.New(stored.Stmt))
+		p2.logr = p.logr
+		stmt := p2.ParseStatement()
+		if stmt == nil || len(p2.perror) > 0 {
+			continue
+		}
+		d = append(d, deprecations(stmt, stored)...)
+	}
+	return d, nil
 }
 
-func (p *Parser) closeLogFile() {
-	if err := p.logf.Close(); err != nil {
-		log.Fatal(err)
+// storedRow returns the row of the statement of the name stored in the document, nil if none is stored.
+// Each row is read once per document.
+func (p *Parser) storedRow(name ast.NameValue_) (*db.TypeRow, error) {
+	if row, ok := p.stored[name]; ok {
+		return row, nil
+	}
+	row, err := db.DBFetchRow(name.String())
+	if errors.Is(err, db.NoItemFoundErr) {
+		row, err = nil, nil
 	}
+	if err != nil {
+		return nil, err
+	}
+	p.stored[name] = row
+	return row, nil
 }
 
-func (p *Parser) ClearCache() {}
+// dbError reports a database error in applying the statement of the name.
+func (p *Parser) dbError(err error, document string, name ast.NameValue_) error {
+	p.logr.Log(logger.Error, err.Error(), logger.Document(document), logger.Type(name.String()), logger.Phase("persist"))
+	return p.addDiag(ast.CodeDatabase, nil, "%w", err)
+}
+
+// dependents returns the statements that reference, directly or through other statements of the document,
+// a statement of the document that has errors.
+func (p *Parser) dependents(api *ast.Document) map[ast.NameValue_]bool {
+	failed := make(map[ast.NameValue_]bool)
+	for name := range api.StatementsMap {
+		if len(api.ErrorMap[name]) > 0 {
+			failed[name] = true
+		}
+	}
+	skipped := make(map[ast.NameValue_]bool)
+	for more := true; more; {
+		more = false
+		for name, refs := range p.refs {
+			if failed[name] || skipped[name] {
+				continue
+			}
+			for ref := range refs {
+				if ref.Name != name && (failed[ref.Name] || skipped[ref.Name]) {
+					skipped[name] = true
+					more = true
+					break
+				}
+			}
+		}
+	}
+	return skipped
+}
+
+// sameStatement reports whether two statements are the same apart from white space and commas.
+func sameStatement(a, b string) bool {
+	la, lb := lexer.New(a).KeepComments(), lexer.New(b).KeepComments()
+	for {
+		ta, tb := la.NextToken(), lb.NextToken()
+		if ta.Type != tb.Type || ta.Literal != tb.Literal || ta.Illegal || tb.Illegal {
+			return false
+		}
+		if ta.Type == token.EOF {
+			return true
+		}
+	}
+}
 
 // ==================== Start =========================
 
@@ -267,75 +549,112 @@ func (p *Parser) ParseDocument(doc ...string) (api *ast.Document, errs []error)
 	api.Statements = []ast.GQLTypeProvider{} // slice is initialised  with no elements - each element represents an interface value of type ast.GQLTypeProvider
 	api.StatementsMap = make(map[ast.NameValue_]ast.GQLTypeProvider)
 	api.ErrorMap = make(map[ast.NameValue_][]error)
-	//
-	// create cache
-	//
-	fmt.Println()
-	fmt.Println("SDL ParseDocument....")
-	fmt.Println()
-	fmt.Println("^^^^^^^ typeNotExists : ", len(typeNotExists))
-	fmt.Println("^^^^^^^ Cache_ :", len(p.cache.Cache))
-	defer p.closeLogFile()
+	p.extended = make(map[ast.NameValue_]ast.GQLTypeProvider)
+	p.refs = make(map[ast.NameValue_]ast.UnresolvedMap)
+	p.stored = make(map[ast.NameValue_]*db.TypeRow)
+	p.validated = false
+	if p.dryRun {
+		p.dryCache = p.cache.entries()
+	}
+	document := defaultDoc
+	if len(doc) > 0 {
+		document = doc[0]
+	}
+	p.logr.Log(logger.Info, "parse document", logger.Document(document), logger.Phase("parse"), logger.Any("cached", len(p.cache.Cache)))
 	defer func() {
 		//
 		//p.perror = nil
 		p.perror = append(p.perror, holderr...)
 		for _, v := range api.StatementsMap { //range api.Statements {
+			setOwner(v.TypeName(), api.ErrorMap[v.TypeName()])
 			p.perror = append(p.perror, api.ErrorMap[v.TypeName()]...)
 		}
-		// persist error free statements to db
-		for _, v := range api.StatementsMap { //api.Statements {
-			if len(api.ErrorMap[v.TypeName()]) == 0 {
-				// TODO - what if another type by that name exists
-				//  auto overrite or raise an error
-				if err := db.Persist(v.TypeName().String(), v); err != nil {
-					p.addErr(err.Error())
-				}
-			}
+		// persist error free statements to db, or in a dry run only report what would be persisted
+		api.Results = p.apply(api, document)
+		if p.dryRun {
+			// the statements of the document are not stored, so must not be found in the cache by a later document,
+			// nor may the stored types read during the dry run, as they are resolved against those statements
+			p.cache.restore(p.dryCache)
+			p.dryCache = nil
 		}
 		//	ast.CacheClear()
 		errs = p.perror
+		p.logr.Log(logger.Info, "parsed document", logger.Document(document), logger.Any("statements", len(api.Statements)), logger.Any("errors", len(errs)))
 	}()
 	//
-	//  open log file and set logger
-	//
-	p.logf = openLogFile()
-	p.logr = log.New(p.logf, "SDL:", logrFlags)
-	p.cache.SetLogger(p.logr)
-	//
 	// set document
 	//
 	db.SetDefaultDoc(defaultDoc)
-	if len(doc) == 0 {
-		db.SetDocument(defaultDoc)
-	} else {
-		db.SetDocument(doc[0])
-	}
+	db.SetDocument(document)
 	//
 	// parse phase - build AST from GraphQL document
 	//
-	p.logr.Println("Start server...")
-	//
+	var nerr int // errors reported by the statements parsed so far
 	for p.curToken.Type != token.EOF {
 		stmtAST := p.ParseStatement()
 
-		// handle any abort error
-		if p.hasError() {
-			return api, p.perror
+		nerr += len(p.perror)
+		if p.errLimitReached(nerr) {
+			// errors are sourced from ErrorMap and holderr, see defer above
+			holderr = append(holderr, p.perror...)
+			p.perror = nil
+			return api, nil
+		}
+		if p.abort {
+			// syntax error - abandon the statement and resume at the next definition
+			holderr = append(holderr, p.perror...)
+			p.perror = nil
+			p.extend = false
+			p.skipToDefinition()
+			continue
 		}
 		if stmtAST != nil {
-			fmt.Printf("Parsed statement: %s %s   (errors: %d) ", stmtAST.Type(), stmtAST.TypeName(), len(p.perror))
-			api.Statements = append(api.Statements, stmtAST)
+			p.logr.Log(logger.Debug, "parsed statement", logger.Document(document), logger.Type(stmtAST.TypeName().String()), logger.Phase("parse"), logger.Any("errors", len(p.perror)))
 			name := stmtAST.TypeName()
-			api.StatementsMap[name] = stmtAST
-			api.ErrorMap[name] = p.perror
-			// add all stmts to cache (even errored ones). This prevents db searches for errored stmts.
-			p.cache.addEntry(stmtAST.TypeName(), stmtAST)
+			// record the references before types are resolved, as a resolved type is not solicited
+			p.refs[name] = make(ast.UnresolvedMap)
+			stmtAST.SolicitAbstractTypes(p.refs[name])
+			if d, ok := stmtAST.(*ast.Directive_); ok && IsBuiltinDirective(name) {
+				// the definition would replace the built-in definition in the cache
+				p.addDiag(ast.CodeDirective, d.Name_.Loc, `Directive "%s" is a built-in directive and cannot be redefined`, name)
+				holderr = append(holderr, p.perror...)
+				p.perror = nil
+				continue
+			}
+			if sc, ok := stmtAST.(*ast.Schema_); ok && !p.extend && api.StatementsMap[name] != nil {
+				// a document defines a single schema, which an extension can add to
+				dup := ast.Diagf(ast.CodeDuplicate, nil, `Schema is already defined in the document. Use "extend schema" to add to it`)
+				dup.Span = sc.Span
+				holderr = append(holderr, append(p.perror, dup)...)
+				p.perror = nil
+				continue
+			}
+			if _, ok := api.StatementsMap[name]; ok && p.extend {
+				// extension to a type defined earlier in the document - the extended copy replaces that statement
+				for i, v := range api.Statements {
+					if v.TypeName() == name {
+						api.Statements[i] = stmtAST
+					}
+				}
+				api.StatementsMap[name] = stmtAST
+				api.ErrorMap[name] = append(api.ErrorMap[name], p.perror...)
+			} else {
+				api.Statements = append(api.Statements, stmtAST)
+				api.StatementsMap[name] = stmtAST
+				api.ErrorMap[name] = p.perror
+			}
+			if p.extend {
+				// the extension is a copy of the cached type. It replaces the cached type only after it validates, see defer above.
+				p.extended[name] = stmtAST
+			} else {
+				// add all stmts to cache (even errored ones). This prevents db searches for errored stmts.
+				p.cacheStatement(name, stmtAST)
+			}
 			p.perror = nil
 
 		} else {
 			// for no statements hold errors
-			holderr = p.perror
+			holderr = append(holderr, p.perror...)
 			p.perror = nil
 		}
 		if p.extend {
@@ -349,7 +668,7 @@ func (p *Parser) ParseDocument(doc ...string) (api *ast.Document, errs []error)
 	//					  if cache returns no value then don't generate error as this was done at cache populate time for that item.
 	//
 	for _, v := range api.Statements {
-		fmt.Println("A out to resolve types for ", v.TypeName())
+		p.logr.Log(logger.Debug, "resolve types", logger.Document(document), logger.Type(v.TypeName().String()), logger.Phase("resolve"))
 		p.resolveDependents(v, p.cache)
 		if len(p.perror) > 0 {
 			api.ErrorMap[v.TypeName()] = append(api.ErrorMap[v.TypeName()], p.perror...)
@@ -367,8 +686,12 @@ func (p *Parser) ParseDocument(doc ...string) (api *ast.Document, errs []error)
 	// 	ast.TyCache[k] = v.data
 	// }
 	LoadASTcache(p.cache)
+	// validate against the extended types, which are not yet in the shared cache
+	for k, v := range p.extended {
+		ast.TyCache[k.String()] = v
+	}
 
-	fmt.Println("*** entries transfered to ast cache - ", len(ast.TyCache))
+	p.logr.Log(logger.Debug, "types cached", logger.Document(document), logger.Phase("resolve"), logger.Any("cached", len(ast.TyCache)))
 	//
 	// Build perror from statement errors to use in hasError() counting
 	//
@@ -410,7 +733,7 @@ func (p *Parser) ParseDocument(doc ...string) (api *ast.Document, errs []error)
 	}
 
 	p.perror = nil
-	fmt.Println("stmt map: ", api.StatementsMap)
+	p.logr.Log(logger.Debug, "validate statements", logger.Document(document), logger.Phase("validate"), logger.Any("statements", len(api.StatementsMap)))
 	for _, v := range api.StatementsMap {
 		if p.hasError() {
 			break
@@ -441,6 +764,7 @@ func (p *Parser) ParseDocument(doc ...string) (api *ast.Document, errs []error)
 		switch x := v.(type) {
 		case *ast.Input_:
 			x.CheckIsInputType(&p.perror)
+			p.CheckInputCycle(x, api.StatementsMap)
 		case *ast.Object_:
 			if p.executeWithErrLimit(x.CheckIsOutputType, 5) {
 				errCollect(v.TypeName())
@@ -455,8 +779,11 @@ func (p *Parser) ParseDocument(doc ...string) (api *ast.Document, errs []error)
 		case *ast.Enum_:
 		case *ast.Interface_:
 			x.CheckFieldMembers(&p.perror)
+			x.CheckImplements(&p.perror) // check implements are interfaces, including transitive and cyclic implements
 		case *ast.Union_:
 			p.CheckUnionMembers(x)
+		case *ast.Schema_:
+			p.CheckSchema(x)
 		case *ast.Directive_:
 			if p.executeWithErrLimit(x.CheckIsInputType, 5) {
 				errCollect(v.TypeName())
@@ -467,6 +794,14 @@ func (p *Parser) ParseDocument(doc ...string) (api *ast.Document, errs []error)
 		//
 		errCollect(v.TypeName())
 	}
+	//
+	// validate phase 4 - members removed from stored types and stored types that depend on a changed type
+	//
+	if !p.hasError() {
+		p.validateRemovals(api)
+		p.validateDependents(api, document)
+		p.validated = true
+	}
 	return api, p.perror
 }
 
@@ -480,14 +815,38 @@ func (p *Parser) ParseDocument(doc ...string) (api *ast.Document, errs []error)
 
 // parseStatement takes predefined parser routine and applies it to a valid statement
 func (p *Parser) ParseStatement() ast.GQLTypeProvider {
-	p.skipComment()
+	start := p.curToken.Loc
+	desc := p.readDescription()
 	if p.curToken.Type == token.EXTEND {
+		if len(desc) > 0 {
+			p.addErr("A description is not permitted on a type extension")
+		}
 		p.extend = true
 		p.nextToken() // read over extend
 	}
 	p.stmtType = strings.ToLower(p.curToken.Literal)
 	if f, ok := p.parseFns[p.curToken.Type]; ok {
-		return f(p.stmtType)
+		leading := p.takeComments(0)
+		p.lastNode = nil
+		stmt := f(p.stmtType)
+		// an extension keeps the span of the original statement
+		if sp, ok := stmt.(ast.SpanAssigner); ok && !p.extend {
+			sp.AssignSpan(p.spanFrom(start))
+		}
+		// extend returns the original AST, which retains its own description
+		if d, ok := stmt.(ast.DescAssigner); ok && !p.extend && len(desc) > 0 {
+			d.AssignDesc(desc)
+		}
+		// comments up to the end of the statement, e.g. a commented out field, trail the statement, as do those at the end of the document
+		if c, ok := stmt.(ast.CommentAssigner); ok {
+			line := p.prevLoc.Line
+			if p.curToken.Type == token.EOF {
+				line = 0
+			}
+			c.AssignComments(leading, p.takeComments(line))
+		}
+		p.lastNode = nil
+		return stmt
 	} else {
 		p.abort = true
 		p.addErr(fmt.Sprintf(`Parse aborted. "%s" is not a statement keyword`, p.stmtType))
@@ -496,6 +855,28 @@ func (p *Parser) ParseStatement() ast.GQLTypeProvider {
 	return nil
 }
 
+// skipToDefinition implements panic-mode error recovery. Tokens are discarded until the start of the next
+// definition, so a syntax error in one statement does not hide the errors in the rest of the document.
+func (p *Parser) skipToDefinition() {
+	p.abort = false
+	for p.curToken.Type != token.EOF && !p.isDefinitionStart() {
+		p.nextToken()
+	}
+}
+
+// isDefinitionStart reports whether the current token is a definition keyword (or extend), or a description preceding one.
+// A keyword followed by a colon or argument list is a field name e.g. "type: String", not the start of a definition.
+func (p *Parser) isDefinitionStart() bool {
+	isKeyword := func(t *token.Token) bool {
+		_, ok := p.parseFns[t.Type]
+		return ok || t.Type == token.EXTEND
+	}
+	if p.isDescription() {
+		return isKeyword(p.peekToken)
+	}
+	return isKeyword(p.curToken) && p.peekToken.Type != token.COLON && p.peekToken.Type != token.LPAREN
+}
+
 // TypeResolveErr used only to categorise the error not to provided extra information.
 var TypeResolveErr = errors.New("")
 
@@ -510,7 +891,6 @@ func (p *Parser) resolveDependents(v ast.GQLTypeProvider, t *Cache_) {
 	// types immediately associated with v, the type under investigation.
 	// ResolveNestedType is called via FetchAST, to walk the graph of nested types beyond v.
 	//
-	fmt.Println("************** ResolveType: ", v.TypeName())
 	nestedAbstractTypes := make(ast.UnresolvedMap)
 	v.SolicitAbstractTypes(nestedAbstractTypes)
 	//
@@ -522,13 +902,19 @@ func (p *Parser) resolveDependents(v ast.GQLTypeProvider, t *Cache_) {
 		// if all ready cached then add to resolved map
 		if _, ok := t.Cache[tyName.String()]; ok {
 			if tyName.Name == v.TypeName() {
-				// remove type that is under consideration from list of types to be resolved.
+				// remove type that is under consideration from list of types to be resolved. A reference to itself
+				// resolves to the type.
+				if gqltype := nestedAbstractTypes[tyName]; gqltype != nil && gqltype.AST == nil {
+					gqltype.Lock()
+					gqltype.AST = v
+					gqltype.Unlock()
+				}
 				delete(nestedAbstractTypes, tyName)
 			}
 		}
 	}
 	t.Unlock()
-	fmt.Println("AbstractType : ", nestedAbstractTypes)
+	p.logr.Log(logger.Debug, "resolve nested types", logger.Type(v.TypeName().String()), logger.Phase("resolve"), logger.Any("nested", len(nestedAbstractTypes)))
 	//
 	//  nestedAbstractTypes should now contain abstract types except current type under investigation.
 	//  As a side effect of this proecssing we populate the AST attribute in the GQLtype when the AST exists.
@@ -543,11 +929,11 @@ func (p *Parser) resolveDependents(v ast.GQLTypeProvider, t *Cache_) {
 			switch {
 			case errors.Is(err, ErrNotCached):
 				//p.addErr2(fmt.Errorf(`Item %q %s in document %q %s %w`, tyName, err, db.GetDocument(), tyName.AtPosition(), TypeResolveErr))
-				p.addErr2(fmt.Errorf(`%q %s in document %q %s %w`, tyName, err, db.GetDocument(), tyName.AtPosition(), TypeResolveErr))
+				p.addDiag(ast.CodeType, tyName.Loc, `%q %s in document %q %w`, tyName, err, db.GetDocument(), TypeResolveErr)
 			case errors.Is(err, db.NoItemFoundErr):
-				p.addErr2(fmt.Errorf(`%s %s %w`, err, tyName.AtPosition(), TypeResolveErr))
+				p.addDiag(ast.CodeType, tyName.Loc, `%s %w`, err, TypeResolveErr)
 			default:
-				p.addErr2(fmt.Errorf(`%s %s %w`, err, tyName.AtPosition(), TypeResolveErr))
+				p.addDiag(ast.CodeType, tyName.Loc, `%s %w`, err, TypeResolveErr)
 			}
 		} else {
 			//
@@ -574,7 +960,7 @@ func (p *Parser) CheckSelfReference(directive ast.NameValue_, x *ast.Directive_)
 	for _, v := range x.ArgumentDefs {
 		for _, dir := range v.Directives {
 			if directive.String() == dir.Name_.String() {
-				p.addErr(fmt.Sprintf(`Directive "%s" that references itself, is not permitted %s`, directive, dir.Name_.AtPosition()))
+				p.addDiag(ast.CodeDirective, dir.Name_.Loc, `Directive "%s" that references itself, is not permitted`, directive)
 			}
 		}
 		if v.Type.AST != nil {
@@ -591,9 +977,9 @@ func (p *Parser) CheckUnionMembers(x *ast.Union_) {
 		ast_, err := p.cache.FetchAST(m.Name)
 		if err != nil { //ast_ == nil || err != nil {
 			if errors.Is(err, ErrNotCached) {
-				p.addErr(fmt.Sprintf(`%s. Union member "%s" does not exist %s`, err, m, m.AtPosition()))
+				p.addDiag(ast.CodeType, m.Loc, `%s. Union member "%s" does not exist`, err, m)
 			} else {
-				p.addErr(fmt.Sprintf(`Union member %s %s %s`, m, err, m.AtPosition()))
+				p.addDiag(ast.CodeType, m.Loc, `Union member %s %s`, m, err)
 			}
 		} else {
 			// Spec: The member types of a Union type must all be Object base types; Scalar, Interface and Union types must not be member types of a Union.
@@ -601,7 +987,7 @@ func (p *Parser) CheckUnionMembers(x *ast.Union_) {
 			switch ast_.(type) {
 			case *ast.Object_: //, *ast.Union_, *ast.Interface_, *ast.Scalar_: //, *ast.Int_, *ast.Float_, *ast.String_, *ast.Boolean_, *ast.ID_:
 			default:
-				p.addErr(fmt.Sprintf(`Union member "%s" must be an object based type %s`, m, m.AtPosition()))
+				p.addDiag(ast.CodeType, m.Loc, `Union member "%s" must be an object based type`, m)
 				// if x, ok := ast_.(ast.InputValueProvider); ok {
 				// 	switch x.(type) {
 				// 	case *ast.Int_, *ast.Float_, *ast.String_, *ast.Bool_, *ast.ID_:
@@ -617,6 +1003,69 @@ func (p *Parser) CheckUnionMembers(x *ast.Union_) {
 	}
 }
 
+// ===================== CheckInputCycle ================
+
+// CheckInputCycle reports a cycle of non-null, non-list fields that leads from input type x back to itself, as no value of x
+// could be written e.g. input A { b: B! } input B { a: A! }. Types referenced are followed through their resolved AST, so
+// the cycle may pass through stored types. Fields of types in doc are shown with their location.
+func (p *Parser) CheckInputCycle(x *ast.Input_, doc map[ast.NameValue_]ast.GQLTypeProvider) {
+	type step struct {
+		in  *ast.Input_
+		fld *ast.InputValueDef
+	}
+	var (
+		path    []step
+		visited = make(map[ast.NameValue_]bool)
+		walk    func(in *ast.Input_) bool
+	)
+	walk = func(in *ast.Input_) bool {
+		visited[in.Name] = true
+		for _, v := range in.InputValueDefs {
+			if v.Type == nil || v.Type.IsList() || v.Type.IsNullable() {
+				continue
+			}
+			next, ok := v.Type.AST.(*ast.Input_)
+			if !ok {
+				if v.Type.AST != nil {
+					continue
+				}
+				ast_, err := p.cache.FetchAST(v.Type.Name)
+				if err != nil {
+					// reported during type resolution
+					continue
+				}
+				if next, ok = ast_.(*ast.Input_); !ok {
+					continue
+				}
+			}
+			path = append(path, step{in, v})
+			if next.Name.Equals(x.Name) {
+				return true
+			}
+			if !visited[next.Name] && walk(next) {
+				return true
+			}
+			path = path[:len(path)-1]
+		}
+		return false
+	}
+	if !walk(x) {
+		return
+	}
+	var s strings.Builder
+	for _, v := range path {
+		s.WriteString(v.in.Name.String() + "." + v.fld.Name.String())
+		if _, ok := doc[v.in.Name]; ok {
+			s.WriteString(" " + v.fld.Name_.Loc.String())
+		} else {
+			s.WriteString(" (stored)")
+		}
+		s.WriteString(" -> ")
+	}
+	s.WriteString(x.Name.String())
+	p.addDiag(ast.CodeType, path[0].fld.Name_.Loc, `Input "%s" cannot be satisfied, its non-null fields form a cycle: %s`, x.Name_, s.String())
+}
+
 var opt bool = true // is optional
 
 // ==================== Schema  ============================
@@ -637,13 +1086,17 @@ func (p *Parser) ParseSchema(op string) ast.GQLTypeProvider {
 
 		return inp
 	} else {
-		// return original AST associated with the extend Name.
-		obj, name, err := p.parseExtendName()
+		// ExtendSchema
+		//		extend schema Directives-opt { RootOperationTypeDefinition-list }
+		//		extend schema Directives
+		// a schema has no name so its original AST is sourced using its TypeName.
+		obj, err := p.fetchExtendAST(ast.NameValue_("schema"))
 		if obj != nil {
 			if inp, ok := obj.(*ast.Schema_); !ok {
-				p.addErr(fmt.Sprintf(`specified extend type "%s" is not an Input Value Type`, obj.TypeName()))
+				p.addDiag(ast.CodeExtend, p.Loc(), `specified extend type "%s" is not a Schema`, obj.TypeName())
 				p.abort = true
 			} else {
+				inp = inp.Clone() // extend a copy. The cached original is replaced only once the extension validates.
 				d := inp.Directives_.Len()
 
 				p.parseDirectives(inp, opt)
@@ -656,8 +1109,9 @@ func (p *Parser) ParseSchema(op string) ast.GQLTypeProvider {
 				return inp
 			}
 		} else {
-			p.addErr(strings.Replace(err.Error(), "Item", "Schema", 1) + p.Loc().String())
-			return &ast.Object_{Name_: name}
+			p.addDiag(ast.CodeExtend, p.Loc(), "%s", strings.Replace(err.Error(), "Item", "Schema", 1))
+			p.abort = true
+			return &ast.Schema_{}
 		}
 	}
 	return nil
@@ -671,8 +1125,8 @@ func (p *Parser) parseOperationTypes(v *ast.Schema_, optional ...bool) *Parser {
 		return p
 	}
 	if p.curToken.Type != token.LBRACE {
-		if len(optional) != 0 {
-			p.addErr(fmt.Sprintf("Expected a ( instead got %s", p.curToken.Type))
+		if len(optional) == 0 {
+			p.addErr(fmt.Sprintf("Expected a { instead got %s", p.curToken.Type))
 		}
 		return p
 	}
@@ -691,23 +1145,84 @@ func (p *Parser) parseOperationTypes(v *ast.Schema_, optional ...bool) *Parser {
 }
 
 func (p *Parser) parseOperation(inp *ast.Schema_) *Parser {
+	var defined bool
 
 	switch p.curToken.Type {
 	case token.QUERY:
 		inp.Op = ast.QUERY_OP
+		defined = inp.Query.Exists()
 	case token.MUTATION:
 		inp.Op = ast.MUTATION_OP
+		defined = inp.Mutation.Exists()
 	case token.SUBSCRIPTION:
 		inp.Op = ast.SUBSCRIPTION_OP
+		defined = inp.Subscription.Exists()
 	default:
 		p.addErr(fmt.Sprintf("%s is not a valid operation. Must be query, mutation or subscription ", p.curToken.Type))
 	}
+	if defined {
+		// either repeated in the schema definition or introduced by an extension to a schema that already defines it
+		p.addDiag(ast.CodeDuplicate, p.Loc(), `Schema operation type "%s" is already defined`, p.curToken.Literal)
+		inp.Op = 0 // retain the original definition
+	}
 
 	p.nextToken() // read over op type
 
 	return p
 }
 
+// CheckSchema validates the root operation types of the schema. A query root type is required and each root type must be a
+// distinct Object type. Root types that do not exist are reported when the schema is resolved.
+func (p *Parser) CheckSchema(x *ast.Schema_) {
+	if !x.Query.Exists() {
+		loc := &ast.Loc_{Line: x.Span.Start.Line, Column: x.Span.Start.Column, Offset: x.Span.Start.Offset, File: x.Span.File}
+		p.addDiag(ast.CodeType, loc, `Schema must define a query root operation type`)
+	}
+	bound := make(map[ast.NameValue_]string)
+	for _, v := range []struct {
+		op   string
+		root ast.Name_
+	}{{"query", x.Query}, {"mutation", x.Mutation}, {"subscription", x.Subscription}} {
+		if !v.root.Exists() {
+			continue
+		}
+		if op, ok := bound[v.root.Name]; ok {
+			p.addDiag(ast.CodeType, v.root.Loc, `Schema %s root type "%s" is already the %s root type. Root types must be distinct`, v.op, v.root, op)
+			continue
+		}
+		bound[v.root.Name] = v.op
+		t, err := p.cache.FetchAST(v.root.Name)
+		if err != nil {
+			continue
+		}
+		if _, ok := t.(*ast.Object_); !ok {
+			p.addDiag(ast.CodeType, v.root.Loc, `Schema %s root type "%s" must be an Object type`, v.op, v.root)
+		}
+	}
+}
+
+// Schema returns the schema that binds the root operation types. Without a schema definition the Object types named
+// Query, Mutation and Subscription are bound as the root types of their operation.
+func (p *Parser) Schema() *ast.Schema_ {
+	if ast_, err := p.cache.FetchAST(ast.NameValue_("schema")); err == nil {
+		if sc, ok := ast_.(*ast.Schema_); ok {
+			return sc
+		}
+	}
+	sc := &ast.Schema_{}
+	for _, v := range []struct {
+		name ast.NameValue_
+		root *ast.Name_
+	}{{"Query", &sc.Query}, {"Mutation", &sc.Mutation}, {"Subscription", &sc.Subscription}} {
+		if ast_, err := p.cache.FetchAST(v.name); err == nil {
+			if _, ok := ast_.(*ast.Object_); ok {
+				v.root.Name = v.name
+			}
+		}
+	}
+	return sc
+}
+
 // checkFieldASTAssigned return false if AST is not assigned. Further validations should not be carried out if AST is not assigned
 func (p *Parser) checkFieldASTAssigned(stmt ast.GQLTypeProvider) bool {
 
@@ -741,7 +1256,6 @@ func (p *Parser) ParseObjectType(op string) ast.GQLTypeProvider {
 
 	p.state = parseObjectType
 	p.nextToken() // read over type
-	fmt.Println("parseObjectType..........")
 	if !p.extend {
 		obj := &ast.Object_{}
 
@@ -753,20 +1267,21 @@ func (p *Parser) ParseObjectType(op string) ast.GQLTypeProvider {
 		obj, name, err := p.parseExtendName()
 		if obj != nil {
 			if inp, ok := obj.(*ast.Object_); !ok {
-				p.addErr(fmt.Sprintf(`specified extend type "%s" is not an Input Value Type`, obj.TypeName()))
+				p.addDiag(ast.CodeExtend, name.Loc, `specified extend type "%s" is not an Object type`, obj.TypeName())
 				p.abort = true
 			} else {
+				inp = inp.Clone() // extend a copy. The cached original is replaced only once the extension validates.
 				icnt, dcnt, fcnt := len(inp.Implements), len(inp.Directives), len(inp.FieldSet)
 
 				p.parseImplements(inp, opt).parseDirectives(inp, opt).parseFields(inp, opt)
 
 				if icnt == len(inp.Implements) && dcnt == len(inp.Directives) && fcnt == len(inp.FieldSet) {
-					p.addErr(fmt.Sprintf(`extend for type "%s" contains no changes`, inp.TypeName()))
+					p.addDiag(ast.CodeExtend, name.Loc, `extend for type "%s" contains no changes`, inp.TypeName())
 				}
 				return inp
 			}
 		} else {
-			p.addErr(strings.Replace(err.Error(), "Item", "Type", 1) + p.Loc().String())
+			p.addDiag(ast.CodeExtend, p.Loc(), "%s", strings.Replace(err.Error(), "Item", "Type", 1))
 			return &ast.Object_{Name_: name}
 		}
 	}
@@ -797,20 +1312,21 @@ func (p *Parser) ParseEnumType(op string) ast.GQLTypeProvider {
 		obj, name, err := p.parseExtendName()
 		if obj != nil {
 			if inp, ok := obj.(*ast.Enum_); !ok {
-				p.addErr(fmt.Sprintf(`specified extend type "%s" is not an Input Value Type`, obj.TypeName()))
+				p.addDiag(ast.CodeExtend, name.Loc, `specified extend type "%s" is not an Enum type`, obj.TypeName())
 				p.abort = true
 			} else {
+				inp = inp.Clone() // extend a copy. The cached original is replaced only once the extension validates.
 				dcnt, fcnt := len(inp.Directives), len(inp.Values)
 
-				p.parseDirectives(inp, opt).parseEnumValues(inp)
+				p.parseDirectives(inp, opt).parseEnumValues(inp, opt)
 
 				if dcnt == len(inp.Directives) && fcnt == len(inp.Values) {
-					p.addErr(fmt.Sprintf(`extend for type "%s" contains no changes`, inp.TypeName()))
+					p.addDiag(ast.CodeExtend, name.Loc, `extend for type "%s" contains no changes`, inp.TypeName())
 				}
 				return inp
 			}
 		} else {
-			p.addErr(strings.Replace(err.Error(), "Item", "Enum", 1) + p.Loc().String())
+			p.addDiag(ast.CodeExtend, p.Loc(), "%s", strings.Replace(err.Error(), "Item", "Enum", 1))
 			return &ast.Enum_{Name_: name}
 		}
 	}
@@ -819,7 +1335,7 @@ func (p *Parser) ParseEnumType(op string) ast.GQLTypeProvider {
 
 // ====================== Interface ===========================
 // InterfaceTypeDefinition
-//		Description-opt	interface	Name	Directives-opt	FieldsDefinition-opt
+//		Description-opt	interface	Name	ImplementsInterfaces-opt	Directives-opt	FieldsDefinition-opt
 func (p *Parser) ParseInterfaceType(op string) ast.GQLTypeProvider {
 	defer p.setState(p.state)()
 
@@ -828,7 +1344,7 @@ func (p *Parser) ParseInterfaceType(op string) ast.GQLTypeProvider {
 	if !p.extend {
 		obj := &ast.Interface_{}
 
-		p.parseName(obj).parseDirectives(obj, opt).parseFields(obj, opt)
+		p.parseName(obj).parseImplements(obj, opt).parseDirectives(obj, opt).parseFields(obj, opt)
 
 		return obj
 	} else {
@@ -836,20 +1352,21 @@ func (p *Parser) ParseInterfaceType(op string) ast.GQLTypeProvider {
 		obj, name, err := p.parseExtendName()
 		if obj != nil {
 			if inp, ok := obj.(*ast.Interface_); !ok {
-				p.addErr(fmt.Sprintf(`specified extend type "%s" is not an Input Value Type`, obj.TypeName()))
+				p.addDiag(ast.CodeExtend, name.Loc, `specified extend type "%s" is not an Interface type`, obj.TypeName())
 				p.abort = true
 			} else {
-				dcnt, fcnt := len(inp.Directives), len(inp.FieldSet)
+				inp = inp.Clone() // extend a copy. The cached original is replaced only once the extension validates.
+				icnt, dcnt, fcnt := len(inp.Implements), len(inp.Directives), len(inp.FieldSet)
 
-				p.parseDirectives(inp, opt).parseFields(inp, opt)
+				p.parseImplements(inp, opt).parseDirectives(inp, opt).parseFields(inp, opt)
 
-				if dcnt == len(inp.Directives) && fcnt == len(inp.FieldSet) {
-					p.addErr(fmt.Sprintf(`extend for type "%s" contains no changes`, inp.TypeName()))
+				if icnt == len(inp.Implements) && dcnt == len(inp.Directives) && fcnt == len(inp.FieldSet) {
+					p.addDiag(ast.CodeExtend, name.Loc, `extend for type "%s" contains no changes`, inp.TypeName())
 				}
 				return inp
 			}
 		} else {
-			p.addErr(strings.Replace(err.Error(), "Item", "Interface", 1) + p.Loc().String())
+			p.addDiag(ast.CodeExtend, p.Loc(), "%s", strings.Replace(err.Error(), "Item", "Interface", 1))
 			return &ast.Interface_{Name_: name}
 		}
 	}
@@ -878,20 +1395,21 @@ func (p *Parser) ParseUnionType(op string) ast.GQLTypeProvider {
 		obj, name, err := p.parseExtendName()
 		if obj != nil {
 			if inp, ok := obj.(*ast.Union_); !ok {
-				p.addErr(fmt.Sprintf(`specified extend type "%s" is not an Input Value Type`, obj.TypeName()))
+				p.addDiag(ast.CodeExtend, name.Loc, `specified extend type "%s" is not a Union type`, obj.TypeName())
 				p.abort = true
 			} else {
+				inp = inp.Clone() // extend a copy. The cached original is replaced only once the extension validates.
 				dcnt, fcnt := len(inp.Directives), len(inp.NameS)
 
-				p.parseDirectives(inp, opt).parseUnionMembers(inp)
+				p.parseDirectives(inp, opt).parseUnionMembers(inp, opt)
 
 				if dcnt == len(inp.Directives) && fcnt == len(inp.NameS) {
-					p.addErr(fmt.Sprintf(`extend for type "%s" contains no changes`, inp.TypeName()))
+					p.addDiag(ast.CodeExtend, name.Loc, `extend for type "%s" contains no changes`, inp.TypeName())
 				}
 				return inp
 			}
 		} else {
-			p.addErr(strings.Replace(err.Error(), "Item", "Union", 1) + p.Loc().String())
+			p.addDiag(ast.CodeExtend, p.Loc(), "%s", strings.Replace(err.Error(), "Item", "Union", 1))
 			return &ast.Union_{Name_: name}
 		}
 	}
@@ -917,20 +1435,21 @@ func (p *Parser) ParseInputValueType(op string) ast.GQLTypeProvider {
 		obj, name, err := p.parseExtendName()
 		if obj != nil {
 			if inp, ok := obj.(*ast.Input_); !ok {
-				p.addErr(fmt.Sprintf(`specified extend type "%s" is not an Input Value Type`, obj.TypeName()))
+				p.addDiag(ast.CodeExtend, name.Loc, `specified extend type "%s" is not an Input Value Type`, obj.TypeName())
 				p.abort = true
 			} else {
+				inp = inp.Clone() // extend a copy. The cached original is replaced only once the extension validates.
 				dcnt, fcnt := len(inp.Directives), len(inp.InputValueDefs)
 
 				p.parseDirectives(inp, opt).parseInputFieldDefs(inp)
 
 				if dcnt == len(inp.Directives) && fcnt == len(inp.InputValueDefs) {
-					p.addErr(fmt.Sprintf(`extend for type "%s" contains no changes`, inp.TypeName()))
+					p.addDiag(ast.CodeExtend, name.Loc, `extend for type "%s" contains no changes`, inp.TypeName())
 				}
 				return inp
 			}
 		} else {
-			p.addErr(strings.Replace(err.Error(), "Item", "Input type", 1) + p.Loc().String())
+			p.addDiag(ast.CodeExtend, p.Loc(), "%s", strings.Replace(err.Error(), "Item", "Input type", 1))
 			p.abort = true
 			return &ast.Input_{Name_: name}
 		}
@@ -957,21 +1476,22 @@ func (p *Parser) ParseScalar(op string) ast.GQLTypeProvider {
 		// return original AST associated with the extend Name.
 		obj, name, err := p.parseExtendName()
 		if obj != nil {
-			if inp, ok := obj.(*ast.Input_); !ok {
-				p.addErr(fmt.Sprintf(`specified extend type "%s" is not an Input Value Type`, obj.TypeName()))
+			if inp, ok := obj.(*ast.Scalar_); !ok {
+				p.addDiag(ast.CodeExtend, name.Loc, `specified extend type "%s" is not a Scalar type`, obj.TypeName())
 				p.abort = true
 			} else {
+				inp = inp.Clone() // extend a copy. The cached original is replaced only once the extension validates.
 				dcnt := len(inp.Directives)
 
 				p.parseDirectives(inp, opt)
 
 				if dcnt == len(inp.Directives) {
-					p.addErr(fmt.Sprintf(`extend for type "%s" contains no changes`, inp.TypeName()))
+					p.addDiag(ast.CodeExtend, name.Loc, `extend for type "%s" contains no changes`, inp.TypeName())
 				}
 				return inp
 			}
 		} else {
-			p.addErr(strings.Replace(err.Error(), "Item", "Scalar", 1) + p.Loc().String())
+			p.addDiag(ast.CodeExtend, p.Loc(), "%s", strings.Replace(err.Error(), "Item", "Scalar", 1))
 			return &ast.Scalar_{Name: name.String()}
 		}
 	}
@@ -981,7 +1501,7 @@ func (p *Parser) ParseScalar(op string) ast.GQLTypeProvider {
 
 // ====================== Directive_ ===============================
 // DirectiveDefinition
-//	Descriptiono-pt directive @ Name ArgumentsDefinition-opt  on  DirectiveLocations
+//	Descriptiono-pt directive @ Name ArgumentsDefinition-opt repeatable-opt on  DirectiveLocations
 // DirectiveLocations
 //   | optDirectiveLocation
 // DirectiveLocations | DirectiveLocation
@@ -996,7 +1516,7 @@ func (p *Parser) ParseDirective(op string) ast.GQLTypeProvider {
 
 	inp := &ast.Directive_{}
 
-	p.parseAtSign().parseName(inp).parseFieldArgumentDefs(inp).parseOn().parseDirectiveLocations(inp)
+	p.parseAtSign().parseName(inp).parseFieldArgumentDefs(inp).parseRepeatable(inp).parseOn().parseDirectiveLocations(inp)
 
 	if p.hasError() {
 		return nil
@@ -1009,12 +1529,6 @@ func (p *Parser) ParseDirective(op string) ast.GQLTypeProvider {
 
 // =============================================================
 
-func (p *Parser) skipComment() {
-	if p.curToken.Type == token.STRING {
-		p.nextToken() // read over comment string
-	}
-}
-
 func (p *Parser) parseAtSign() *Parser {
 	if p.curToken.Type == token.ATSIGN {
 		p.nextToken() // read over @
@@ -1025,6 +1539,18 @@ func (p *Parser) parseAtSign() *Parser {
 	return p
 }
 
+// parseRepeatable consumes the optional repeatable keyword. As repeatable is not a reserved word it is lexed as an IDENT.
+func (p *Parser) parseRepeatable(d *ast.Directive_) *Parser {
+	if p.hasError() {
+		return p
+	}
+	if p.curToken.Type == token.IDENT && p.curToken.Literal == "repeatable" {
+		d.Repeatable = true
+		p.nextToken() // read over repeatable
+	}
+	return p
+}
+
 func (p *Parser) parseOn() *Parser {
 	if p.curToken.Type == token.ON {
 		p.nextToken() // read over ON
@@ -1035,18 +1561,76 @@ func (p *Parser) parseOn() *Parser {
 	return p
 }
 
+// addComment holds a comment returned by the lexer in comment mode until it can be attached. As the parser reads a token
+// ahead, a comment is attached when the next definition starts or the statement ends, see takeComments.
+func (p *Parser) addComment(c *token.Token) {
+	p.comments = append(p.comments, c)
+}
+
+// takeComments returns the held comments that precede line, or all of them when line is zero. A comment on the line the
+// most recent definition starts on trails that definition instead.
+func (p *Parser) takeComments(line int) []string {
+	var (
+		taken []string
+		held  []*token.Token
+	)
+	for _, c := range p.comments {
+		switch {
+		case p.lastNode != nil && c.Loc.Line == p.lastLoc.Line && c.Loc.File == p.lastLoc.File:
+			p.lastNode.AssignComments(nil, []string{c.Literal})
+		case line == 0 || (c.Loc.Line <= line && c.Loc.File == p.prevLoc.File):
+			taken = append(taken, c.Literal)
+		default:
+			held = append(held, c)
+		}
+	}
+	p.comments = held
+	return taken
+}
+
+// isDescription reports whether the current token is a string literal ("..." or """...""") rather than the String keyword.
+func (p *Parser) isDescription() bool {
+	return (p.curToken.Type == token.STRING || p.curToken.Type == token.RAWSTRING) && p.curToken.Cat == token.VALUE
+}
+
 func (p *Parser) readDescription() string {
 	var s string
-	if p.curToken.Type == token.STRING {
+	if p.isDescription() {
 		s = p.curToken.Literal
-		p.nextToken() // read over comment string
+		p.nextToken() // read over description string
 	}
 	return s
 }
 
-func (p *Parser) parseDescription() *Parser {
-	if p.curToken.Type == token.STRING {
-		p.nextToken() // read over comment string
+// parseDescription assigns the optional description preceding a field, argument, input field or enum value definition.
+func (p *Parser) parseDescription(f ast.DescAssigner) *Parser {
+	if p.hasError() {
+		return p
+	}
+	if desc := p.readDescription(); len(desc) > 0 {
+		f.AssignDesc(desc)
+	}
+	if c, ok := f.(ast.CommentAssigner); ok {
+		// a comment after the start of the definition, on the same line, trails it e.g. "CAT # or a cat". A comment on a
+		// later line, read as the parser looks ahead, is held for the definitions that follow.
+		var (
+			trailing     []string
+			before, held []*token.Token
+		)
+		for _, cm := range p.comments {
+			switch {
+			case cm.Loc.File != p.curToken.Loc.File || cm.Loc.Line < p.curToken.Loc.Line:
+				before = append(before, cm)
+			case cm.Loc.Line == p.curToken.Loc.Line:
+				trailing = append(trailing, cm.Literal)
+			default:
+				held = append(held, cm)
+			}
+		}
+		p.comments = before
+		c.AssignComments(p.takeComments(0), trailing)
+		p.comments = held
+		p.lastNode, p.lastLoc = c, p.curToken.Loc
 	}
 	return p
 }
@@ -1072,6 +1656,8 @@ func (p *Parser) parseName(f ast.NameAssigner) *Parser {
 
 // ==================== parseExtendName ===============================
 // parseExtendName will consume the type name to be extended. Returns the type's AST.
+// parseExtendName returns the AST of the type being extended. The AST is shared with every other parser
+// so the extension must be applied to a Clone of it.
 func (p *Parser) parseExtendName() (ast.GQLTypeProvider, ast.Name_, error) {
 	// if p.hasError() {
 	// 	return nil,
@@ -1088,7 +1674,7 @@ func (p *Parser) parseExtendName() (ast.GQLTypeProvider, ast.Name_, error) {
 		}
 	}
 	name_ := ast.Name_{Name: ast.NameValue_(extName), Loc: p.Loc()}
-	ast, err := p.cache.FetchAST(name_.Name) // ignore error as as ast value of nil means no data found
+	ast, err := p.fetchExtendAST(name_.Name) // ignore error as as ast value of nil means no data found
 	// handle err to calling routine, which can add extra value
 	if ast != nil {
 		p.nextToken() // read over name
@@ -1096,6 +1682,14 @@ func (p *Parser) parseExtendName() (ast.GQLTypeProvider, ast.Name_, error) {
 	return ast, name_, err
 }
 
+// fetchExtendAST returns the latest extension of the named type made earlier in the document, otherwise the cached type.
+func (p *Parser) fetchExtendAST(name ast.NameValue_) (ast.GQLTypeProvider, error) {
+	if x, ok := p.extended[name]; ok {
+		return x, nil
+	}
+	return p.cache.FetchAST(name)
+}
+
 func (p *Parser) parseEnumValues(enum *ast.Enum_, optional ...bool) *Parser {
 	defer p.setState(p.state)()
 
@@ -1108,24 +1702,28 @@ func (p *Parser) parseEnumValues(enum *ast.Enum_, optional ...bool) *Parser {
 	for p.nextToken(); p.curToken.Type != token.RBRACE; {
 
 		ev := &ast.EnumValue_{}
+		start := p.curToken.Loc
 
-		_ = p.parseDescription().parseName(ev).parseDirectives(ev, opt)
+		_ = p.parseDescription(ev).parseName(ev).parseDirectives(ev, opt)
+		ev.AssignSpan(p.spanFrom(start))
 
 		if p.hasError() {
 			break
 		}
-		// check for duplicate values
+		// check for duplicate values, including those introduced by an extension
+		var dup bool
 		for _, v := range enum.Values {
 			if ev.Loc == nil {
 				continue
 			}
 			if v.Name_.String() == ev.Name_.String() {
-				loc := ev.Name_.Loc
-				p.addErr(fmt.Sprintf("Duplicate Enum Value [%s] at line: %d column: %d", ev.Name_.String(), loc.Line, loc.Column))
-				//return p
+				p.addDiag(ast.CodeDuplicate, ev.Name_.Loc, "Duplicate Enum Value [%s]", ev.Name_.String()).WithRelated("first defined here", v.Name_.Loc)
+				dup = true
 			}
 		}
-		enum.Values = append(enum.Values, ev)
+		if !dup {
+			enum.Values = append(enum.Values, ev)
+		}
 		//enumRepo[string(ev.Name)+"|"+string(enum.Name)] = struct{}{}
 
 	}
@@ -1148,7 +1746,7 @@ func (p *Parser) parseDirectiveLocations(d *ast.Directive_) *Parser {
 			p.addErr(fmt.Sprintf("expected directive location identifer, got %s, %s", p.curToken.Type, p.curToken.Literal))
 		} else if p.curToken.Type == token.IDENT {
 			if dl, ok := directiveLocation[p.curToken.Literal]; !ok {
-				p.addErr(fmt.Sprintf(`Invalid directive location "%s" %s`, p.curToken.Literal, p.Loc()))
+				p.addDiag(ast.CodeDirective, p.Loc(), `Invalid directive location "%s"`, p.curToken.Literal)
 			} else {
 				d.Location = append(d.Location, dl)
 			}
@@ -1165,23 +1763,24 @@ func (p *Parser) parseUnionMembers(u *ast.Union_, optional ...bool) *Parser {
 	defer p.setState(p.state)()
 
 	p.state = parseUnionMembers_
-	if p.curToken.Type != token.ASSIGN {
-		p.abort = true
-	}
 	if p.hasError() {
 		return p
 	}
+	if p.curToken.Type != token.ASSIGN {
+		if len(optional) == 0 {
+			p.addErr(fmt.Sprintf("Expected = followed by union members, got %s", p.curToken.Literal))
+		}
+		return p
+	}
 	for p.nextToken(); p.curToken.Type == token.BAR || p.curToken.Type == token.IDENT; p.nextToken() {
 		if p.curToken.Type == token.BAR && p.peekToken.Type != token.IDENT {
 			p.addErr(fmt.Sprintf("expected Union  member  identifer, got %s, %s", p.curToken.Type, p.curToken.Literal))
 		} else if p.curToken.Type == token.IDENT {
 			var memberName ast.Name_
 			memberName.AssignName(p.curToken.Literal, p.Loc(), &p.perror) // appends to perror if invalid name
-			for _, v := range u.NameS {
-				if v.String() == memberName.String() {
-					loc := memberName.Loc
-					p.addErr(fmt.Sprintf("Duplicate member name at line: %d column: %d", loc.Line, loc.Column))
-				}
+			if u.NameS.Contains(memberName.Name) {
+				p.addDiag(ast.CodeDuplicate, memberName.Loc, "Duplicate member name")
+				continue
 			}
 			u.NameS = append(u.NameS, memberName) // save string component of Name_
 		}
@@ -1195,8 +1794,7 @@ func (p *Parser) parseUnionMembers(u *ast.Union_, optional ...bool) *Parser {
 // ImplementsInterfaces
 //		implements &-opt NamedType
 //		ImplementsInterfaces & NamedType
-//func (p *Parser) parseImplements(f ast.ImplementI, optional ...bool) *Parser {
-func (p *Parser) parseImplements(o *ast.Object_, optional ...bool) *Parser {
+func (p *Parser) parseImplements(f ast.ImplementsAppender, optional ...bool) *Parser {
 	defer p.setState(p.state)()
 
 	p.state = parseImplements_
@@ -1216,13 +1814,9 @@ func (p *Parser) parseImplements(o *ast.Object_, optional ...bool) *Parser {
 		} else if p.curToken.Type == token.IDENT {
 			var impName ast.Name_
 			impName.AssignName(p.curToken.Literal, p.Loc(), &p.perror) // appends to perror if invalid name
-			for _, v := range o.Implements {
-				if v.String() == impName.String() {
-					loc := impName.Loc
-					p.addErr(fmt.Sprintf("Duplicate interface name at line: %d column: %d", loc.Line, loc.Column))
-				}
+			if err := f.AppendImplements(impName); err != nil {
+				p.addErr2(err)
 			}
-			o.Implements = append(o.Implements, impName) // save string component of Name_
 		}
 	}
 	return p
@@ -1265,7 +1859,7 @@ func (p *Parser) parseDirectives(f ast.DirectiveAppender, optional ...bool) *Par
 		}
 
 		if err := f.AppendDirective(d); err != nil {
-			p.addErr(err.Error())
+			p.addErr2(err)
 		}
 	}
 	return p
@@ -1344,6 +1938,8 @@ func (p *Parser) parseColon() *Parser {
 			next = "a directive argument value"
 		case parseArguments_:
 			next = "an argument value"
+		case parseVariableDefs_:
+			next = "a variable type"
 		default:
 			next = strconv.Itoa(int(p.state))
 		}
@@ -1363,7 +1959,8 @@ func (p *Parser) parseInputValue(v *ast.ArgumentT) *Parser {
 	}
 	if !((p.curToken.Cat == token.VALUE && (p.curToken.Type == token.DOLLAR && p.peekToken.Cat == token.VALUE)) ||
 		(p.curToken.Cat == token.VALUE && (p.peekToken.Cat == token.NONVALUE || p.peekToken.Type == token.RPAREN)) ||
-		(p.curToken.Type == token.LBRACKET || p.curToken.Type == token.LBRACE)) { // [  or {
+		(p.curToken.Type == token.LBRACKET || p.curToken.Type == token.LBRACE) || // [  or {
+		p.curToken.Type == token.IDENT) { // enum value
 		p.addErr(fmt.Sprintf(`Expected an argument value followed by an identifer or close parenthesis got "%s"`, p.curToken.Literal))
 	}
 	v.Value = p.parseInputValue_()
@@ -1379,7 +1976,6 @@ func (p *Parser) parseFields(f ast.FieldAppender, optional ...bool) *Parser {
 
 	p.state = parseFields_
 	if p.hasError() || p.curToken.Type != token.LBRACE {
-		fmt.Println("here..", len(optional))
 		if len(optional) == 0 {
 			p.addErr("Field definitions is required")
 		}
@@ -1389,25 +1985,22 @@ func (p *Parser) parseFields(f ast.FieldAppender, optional ...bool) *Parser {
 	for p.nextToken(); p.curToken.Type != token.RBRACE; { // p.nextToken("next token in parseFields..") {
 
 		field := &ast.Field_{}
+		start := p.curToken.Loc
 
-		_ = p.parseDecription().parseName(field).parseFieldArgumentDefs(field).parseColon().parseType(field).parseDirectives(field, opt)
+		_ = p.parseDescription(field).parseName(field).parseFieldArgumentDefs(field).parseColon().parseType(field).parseDirectives(field, opt)
+		field.AssignSpan(p.spanFrom(start))
 
 		if p.hasError() {
 			return p
 		}
 		if err := f.AppendField(field); err != nil {
-			p.addErr(err.Error())
+			p.addErr2(err)
 		}
 	}
 	p.nextToken() // read over }
 	return p
 }
 
-func (p *Parser) parseDecription() *Parser {
-	p.skipComment()
-	return p
-}
-
 // ===================== parseType ===========================
 
 // func  (p *Parser) ParseType(p ParserI, f ast.AssignTyper) {
@@ -1446,6 +2039,7 @@ func (p *Parser) parseType(f ast.AssignTyper) *Parser {
 		depth   uint8
 		nameLoc *ast.Loc_
 	)
+	start := p.curToken.Loc
 	nameLoc = p.Loc()
 	switch p.curToken.Type {
 
@@ -1477,7 +2071,6 @@ func (p *Parser) parseType(f ast.AssignTyper) *Parser {
 		for bangs := uint8(0); p.curToken.Type == token.RBRACKET || p.curToken.Type == token.BANG; {
 			if p.curToken.Type == token.BANG {
 				bangs++
-				fmt.Println("parseType: bangs:", bangs)
 				if bangs > depth+1 {
 					p.addErr("redundant !")
 					p.nextToken() // read over !
@@ -1515,6 +2108,7 @@ func (p *Parser) parseType(f ast.AssignTyper) *Parser {
 	// name is the type name Int, Person, [name], ...
 	t := &ast.GQLtype{Constraint: bit, Depth: depth} //, AST: ast_}
 	t.AssignName(name, nameLoc, &p.perror)
+	t.AssignSpan(p.spanFrom(start))
 	f.AssignType(t) // assign the name of the named type. Later type validation pass of AST will confirm if the named type exists.
 
 	return p
@@ -1568,8 +2162,10 @@ func (p *Parser) parseArgumentDefs(f ast.FieldArgAppender, encl [2]token.TokenTy
 			//for p.curToken.Type != ":" { //TODP fix should be encl[1]
 			v := &ast.InputValueDef{}
 			v.Loc = p.Loc()
-			//	p.parseDecription().parseName(v).parseType(v).parseDefaultVal(v, opt).parseDirectives(v, opt)
-			p.parseDecription().parseName(v).parseColon().parseType(v).parseDefaultVal(v, opt).parseDirectives(v, opt)
+			start := p.curToken.Loc
+			//	p.parseDescription(v).parseName(v).parseType(v).parseDefaultVal(v, opt).parseDirectives(v, opt)
+			p.parseDescription(v).parseName(v).parseColon().parseType(v).parseDefaultVal(v, opt).parseDirectives(v, opt)
+			v.AssignSpan(p.spanFrom(start))
 			if p.hasError() {
 				return p
 			}
@@ -1626,7 +2222,6 @@ func (p *Parser) parseDefaultVal(v *ast.InputValueDef, optional ...bool) *Parser
 		// }
 	}
 
-	fmt.Printf("Default Val: %#v\n", v.DefaultVal)
 	return p
 }
 
@@ -1655,15 +2250,23 @@ func (p *Parser) parseObjectArguments(argS []*ast.ArgumentT) []*ast.ArgumentT {
 //  if it is a variable then the variable value (which is an InputValue_ type) will be sourced
 //  TODO: currently called from parseArgument only. If this continues to be the case then add this func as anonymous func to it.
 //func (p *Parser) parseInputValue_(iv ...*ast.InputValueDef) *ast.InputValue_ { //TODO remove iv argeument now redundant
-func (p *Parser) parseInputValue_() *ast.InputValue_ {
+func (p *Parser) parseInputValue_() (value *ast.InputValue_) {
 	defer p.nextToken() // this func will finish paused on next token - always
 	defer p.setState(p.state)()
 
+	start := p.curToken.Loc
+	defer func() {
+		// the value ends with the current token e.g. the closing ] of a list
+		if value != nil {
+			end := p.curToken.End
+			value.AssignSpan(ast.Span{File: start.File, Start: ast.Pos{Line: start.Line, Column: start.Col, Offset: start.Offset}, End: ast.Pos{Line: end.Line, Column: end.Col, Offset: end.Offset}})
+		}
+	}()
+
 	p.state = parseInputValue__
 	if p.hasError() {
 		return nil
 	}
-	fmt.Println("parseInputValue_............................", p.curToken.Type, p.curToken.Literal)
 	if p.curToken.Type == "ILLEGAL" {
 		p.addErr(fmt.Sprintf("Value expected got %s of %s", p.curToken.Type, p.curToken.Literal))
 		p.abort = true
@@ -1749,6 +2352,17 @@ func (p *Parser) parseInputValue_() *ast.InputValue_ {
 		}
 		iv := ast.InputValue_{InputValueProvider: b, Loc: p.Loc()}
 		return &iv
+	case token.DOLLAR:
+		// $variable
+		loc := p.Loc()
+		p.nextToken() // read over $
+		if !p.exec || p.constant {
+			p.addErr(fmt.Sprintf(`Variable "$%s" is not permitted in a constant value`, p.curToken.Literal))
+		}
+		var v ast.Variable_
+		v.AssignName(p.curToken.Literal, p.Loc(), &p.perror) // deferred nextToken reads over name
+		iv := ast.InputValue_{InputValueProvider: v, Loc: loc}
+		return &iv
 	// case token.Time:
 	// 	b := ast.Time_(p.curToken.Literal)
 	// 	iv := ast.InputValue_{Value: b, Loc: p.Loc()}
@@ -8,6 +8,7 @@ import (
 	"github.com/rosshpayne/graph-sdl/ast"
 	"github.com/rosshpayne/graph-sdl/internal/db"
 	"github.com/rosshpayne/graph-sdl/lexer"
+	"github.com/rosshpayne/graph-sdl/logger"
 )
 
 // for GL types only
@@ -19,16 +20,18 @@ type entry struct {
 type Cache_ struct {
 	sync.Mutex                   // Mutex protects whole cache. Channels protect individual cache entries.
 	Cache      map[string]*entry // cache holds any AST accessed by its name such as types, statements in a doc.
-	logr       *log.Logger
+	logr       logger.Logger
 }
 
 // instance of a cache. This is shared amoungst all parser and query executers.
 var cache *Cache_
 
-func (tc *Cache_) SetLogger(logr *log.Logger) {
-	if tc.logr == nil {
-		tc.logr = logr
+// SetLogger sets the logger of the cache. A nil logger silences logging.
+func (tc *Cache_) SetLogger(logr logger.Logger) {
+	if logr == nil {
+		logr = logger.Nop
 	}
+	tc.logr = logr
 }
 
 // init creates two caches, the not-exists cache which contain all types that do not exist in the current document or in the document being parsed.
@@ -36,7 +39,7 @@ func (tc *Cache_) SetLogger(logr *log.Logger) {
 // The cache exists at the package level, so is available to each parser. The alternate design is to not use init and create the caches in NewCache() below.
 func init() {
 	typeNotExists = make(map[string]bool)
-	cache = &Cache_{Cache: make(map[string]*entry)}
+	cache = &Cache_{Cache: make(map[string]*entry), logr: logger.Nop}
 }
 
 // NewCache allocates a structure to hold the cached data with access methods.
@@ -60,7 +63,34 @@ func (t *Cache_) addEntry(name ast.NameValue_, data ast.GQLTypeProvider) { //ast
 	// add to type cache
 	t.Cache[name.String()] = e
 	t.Unlock()
-	t.logr.Println("addEntry:  Added to Type cache ", name)
+	t.logr.Log(logger.Debug, "cache type", logger.Type(name.String()))
+}
+
+// lookup returns the cached entry of name, or nil.
+func (t *Cache_) lookup(name ast.NameValue_) *entry {
+	t.Lock()
+	defer t.Unlock()
+	return t.Cache[name.String()]
+}
+
+// entries returns a copy of the entries of the cache, see restore.
+func (t *Cache_) entries() map[string]*entry {
+	t.Lock()
+	defer t.Unlock()
+	c := make(map[string]*entry, len(t.Cache))
+	for k, e := range t.Cache {
+		c[k] = e
+	}
+	return c
+}
+
+// restore puts back the entries copied by entries. Each entry added since is removed, including a type read from
+// the database, as it may be resolved against an entry that is removed.
+func (t *Cache_) restore(entries map[string]*entry) {
+	t.Lock()
+	t.Cache = entries
+	t.Unlock()
+	t.logr.Log(logger.Debug, "restore cache", logger.Any("cached", len(entries)))
 }
 
 var (
@@ -79,11 +109,10 @@ func (t *Cache_) FetchAST(name ast.NameValue_) (ast.GQLTypeProvider, error) {
 
 	name_ := name.String()
 	//
-	t.logr.Println("FetchAST: ", name)
+	t.logr.Log(logger.Debug, "fetch type", logger.Type(name_))
 	// do not handle scalars or nul name
 	switch name_ {
 	case "String", "Int", "Float", "Boolean", "ID", "null":
-		t.logr.Println("FetchAST: scalar value abort...")
 		return nil, ErrnotScalar
 	}
 	if len(name) == 0 {
@@ -91,7 +120,7 @@ func (t *Cache_) FetchAST(name ast.NameValue_) (ast.GQLTypeProvider, error) {
 	}
 	// check if name has been registered as non-existent from previous query
 	if typeNotExists[name_] {
-		t.logr.Printf("FetchAST: DBFetch of [%s] does not exist\n", name)
+		t.logr.Log(logger.Debug, "type does not exist", logger.Type(name_))
 		return nil, ErrNotCached
 	}
 	t.Lock()
@@ -104,32 +133,34 @@ func (t *Cache_) FetchAST(name ast.NameValue_) (ast.GQLTypeProvider, error) {
 		t.Cache[name_] = e
 		t.Unlock()
 		// cache populated with bare minimum of data.  Release the lock and source remaining data to be cached while the channel synchronises access to the current entry.
-		// access db for definition of type (string value)
-		if typeSDL, err := db.DBFetch(name_); err != nil {
+		// access db for definition of type (string value), unless it is built-in
+		if typeSDL, err := fetchSDL(name_); err != nil {
 			switch {
 			case errors.Is(err, db.SystemErr), errors.Is(err, db.MarshalingErr), errors.Is(err, db.UnmarshalingErr):
-				t.logr.Fatal(err)
+				t.logr.Log(logger.Error, err.Error(), logger.Type(name_))
+				log.Fatal(err)
 			}
 			typeNotExists[name_] = true
 			delete(t.Cache, name_)
 			close(e.ready)
 			if errors.Is(err, db.NoItemFoundErr) {
-				t.logr.Print(err)
+				t.logr.Log(logger.Debug, "type not in database", logger.Type(name_))
 			}
 			return nil, err
 		} else {
 			if len(typeSDL) == 0 { // no type found in DB
 				// mark type as being nonexistent
-				t.logr.Print("Type not found ")
+				t.logr.Log(logger.Debug, "type not in database", logger.Type(name_))
 				typeNotExists[name_] = true
 				delete(t.Cache, name_)
 				close(e.ready)
 				return nil, err
 			} else {
-				t.logr.Printf("FetchAST:  returned from DB: %q\n", typeSDL)
+				t.logr.Log(logger.Debug, "type read from database", logger.Type(name_), logger.Any("sdl", typeSDL))
 				// generate AST for the resolved type
 				l := lexer.New(typeSDL)
 				p2 := New(l)
+				p2.logr = t.logr
 				//
 				// Generate AST for name of stmt or a GQL type and save to cache
 				// Important: source of stmt is db so its been verified, simply resolve types it refs
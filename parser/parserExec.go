@@ -0,0 +1,255 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rosshpayne/graph-sdl/ast"
+	"github.com/rosshpayne/graph-sdl/internal/token"
+)
+
+// ==================== Executable Document ============================
+
+// ParseExecutable builds the AST of an executable document i.e. the operations and fragments of a client request.
+// It only parses the document. Neither the cache nor the database is accessed.
+//
+// ExecutableDocument
+//		ExecutableDefinition-list
+// ExecutableDefinition
+//		OperationDefinition
+//		FragmentDefinition
+func (p *Parser) ParseExecutable() (*ast.ExecDocument, []error) {
+	doc := &ast.ExecDocument{}
+	p.exec = true
+	defer func() { p.exec = false }()
+
+	for p.curToken.Type != token.EOF {
+		switch p.curToken.Type {
+		case token.LBRACE, token.QUERY, token.MUTATION, token.SUBSCRIPTION:
+			doc.Operations = append(doc.Operations, p.parseOperationDef())
+		case token.IDENT:
+			if p.curToken.Literal == "fragment" {
+				doc.Fragments = append(doc.Fragments, p.parseFragmentDef())
+				break
+			}
+			fallthrough
+		default:
+			p.addErr(fmt.Sprintf(`Parse aborted. "%s" is not an operation or fragment`, p.curToken.Literal))
+			p.abort = true
+		}
+		if p.hasError() {
+			break
+		}
+	}
+	return doc, p.perror
+}
+
+// OperationDefinition
+//		OperationType Name-opt VariableDefinitions-opt Directives-opt SelectionSet
+//		SelectionSet
+func (p *Parser) parseOperationDef() *ast.OperationStmt {
+
+	op := &ast.OperationStmt{OpType: "query"}
+	op.Loc = p.Loc()
+
+	if p.curToken.Type == token.LBRACE {
+		// query shorthand
+		p.parseSelectionSet(op)
+		return op
+	}
+	op.OpType = strings.ToLower(p.curToken.Literal)
+	p.nextToken() // read over query, mutation, subscription
+
+	if p.curToken.Type == token.IDENT {
+		p.parseName(op)
+	}
+	p.parseVariableDefs(op).parseDirectives(op, opt).parseSelectionSet(op)
+
+	return op
+}
+
+// VariableDefinitions
+//		( VariableDefinition-list )
+// VariableDefinition
+//		Variable : Type DefaultValue-opt Directives-opt
+func (p *Parser) parseVariableDefs(op *ast.OperationStmt) *Parser {
+	defer p.setState(p.state)()
+
+	p.state = parseVariableDefs_
+	if p.hasError() || p.curToken.Type != token.LPAREN {
+		return p
+	}
+	for p.nextToken(); p.curToken.Type != token.RPAREN; {
+		if p.curToken.Type != token.DOLLAR {
+			p.addErr(fmt.Sprintf(`Expected a variable, $name, got "%s"`, p.curToken.Literal), FATAL)
+			return p
+		}
+		p.nextToken() // read over $
+
+		v := &ast.VariableDef{}
+
+		p.parseName(v).parseColon().parseType(v).parseVariableDefault(v).parseDirectives(v, opt)
+
+		if p.hasError() {
+			return p
+		}
+		op.VariableDefs = append(op.VariableDefs, v)
+	}
+	p.nextToken() // read over )
+	return p
+}
+
+// DefaultValue
+//		= Value[Const]
+func (p *Parser) parseVariableDefault(v *ast.VariableDef) *Parser {
+	if p.hasError() || p.curToken.Type != token.ASSIGN {
+		return p
+	}
+	p.nextToken() // read over =
+	p.constant = true
+	v.DefaultVal = p.parseInputValue_()
+	p.constant = false
+
+	return p
+}
+
+// SelectionSet
+//		{ Selection-list }
+// Selection
+//		Field
+//		FragmentSpread
+//		InlineFragment
+func (p *Parser) parseSelectionSet(f ast.SelectionAppender, optional ...bool) *Parser {
+	defer p.setState(p.state)()
+
+	p.state = parseSelectionSet_
+	if p.hasError() {
+		return p
+	}
+	if p.curToken.Type != token.LBRACE {
+		if len(optional) == 0 {
+			p.addErr(fmt.Sprintf(`Expected a selection set, got "%s"`, p.curToken.Literal), FATAL)
+		}
+		return p
+	}
+	p.nextToken() // read over {
+	if p.curToken.Type == token.RBRACE {
+		p.addErr("A selection set must contain at least one field or fragment")
+	}
+	for p.curToken.Type != token.RBRACE {
+		var sel ast.SelectionSetI
+
+		switch {
+		case p.curToken.Type == token.EXPAND:
+			sel = p.parseFragmentSelection()
+		case p.isName():
+			sel = p.parseField()
+		default:
+			p.addErr(fmt.Sprintf(`Expected a field or fragment, got "%s"`, p.curToken.Literal), FATAL)
+		}
+		if p.hasError() {
+			return p
+		}
+		f.AppendSelection(sel)
+	}
+	p.nextToken() // read over }
+	return p
+}
+
+// Field
+//		Alias-opt Name Arguments-opt Directives-opt SelectionSet-opt
+// Alias
+//		Name :
+func (p *Parser) parseField() *ast.Field {
+
+	f := &ast.Field{}
+	if p.peekToken.Type == token.COLON {
+		p.parseFieldName(&f.Alias)
+		p.nextToken() // read over :
+	}
+	p.parseFieldName(f).parseArguments(f, opt).parseDirectives(f, opt).parseSelectionSet(f, opt)
+
+	return f
+}
+
+// FragmentSpread
+//		... FragmentName Directives-opt
+// InlineFragment
+//		... TypeCondition-opt Directives-opt SelectionSet
+func (p *Parser) parseFragmentSelection() ast.SelectionSetI {
+
+	loc := p.Loc()
+	p.nextToken() // read over ...
+
+	switch p.curToken.Type {
+	case token.ON, token.ATSIGN, token.LBRACE:
+		f := &ast.InlineFragment{Loc: loc}
+
+		p.parseTypeCondition(&f.TypeCond, opt).parseDirectives(f, opt).parseSelectionSet(f)
+
+		return f
+	}
+	f := &ast.FragmentSpread{}
+
+	p.parseFieldName(f).parseDirectives(f, opt)
+
+	return f
+}
+
+// FragmentDefinition
+//		fragment FragmentName TypeCondition Directives-opt SelectionSet
+func (p *Parser) parseFragmentDef() *ast.FragmentStmt {
+
+	p.nextToken() // read over fragment
+
+	f := &ast.FragmentStmt{}
+	if p.curToken.Type == token.ON {
+		p.addErr(`A fragment cannot be named "on"`)
+	}
+	p.parseFieldName(f).parseTypeCondition(&f.TypeCond).parseDirectives(f, opt).parseSelectionSet(f)
+
+	return f
+}
+
+// TypeCondition
+//		on NamedType
+func (p *Parser) parseTypeCondition(n *ast.Name_, optional ...bool) *Parser {
+	defer p.setState(p.state)()
+
+	p.state = parseTypeCondition_
+	if p.hasError() {
+		return p
+	}
+	if p.curToken.Type != token.ON {
+		if len(optional) == 0 {
+			p.addErr(fmt.Sprintf(`Expected a type condition, "on" Type, got "%s"`, p.curToken.Literal))
+		}
+		return p
+	}
+	p.nextToken() // read over on
+
+	return p.parseName(n)
+}
+
+// isName reports whether the current token is a name. Outside of the type system keywords are names too e.g. a field named type.
+func (p *Parser) isName() bool {
+	if p.isDescription() {
+		return false
+	}
+	tt, _, _ := token.LookupIdent(p.curToken.Literal)
+	return tt == p.curToken.Type
+}
+
+// parseFieldName is parseName for names that may be keywords i.e. field names, aliases and fragment names.
+func (p *Parser) parseFieldName(f ast.NameAssigner) *Parser {
+	if p.hasError() {
+		return p
+	}
+	if !p.isName() {
+		return p.parseName(f) // reports error
+	}
+	f.AssignName(p.curToken.Literal, p.Loc(), &p.perror)
+	p.nextToken() // read over name
+
+	return p
+}
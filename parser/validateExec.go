@@ -0,0 +1,568 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rosshpayne/graph-sdl/ast"
+	"github.com/rosshpayne/graph-sdl/internal/db"
+	"github.com/rosshpayne/graph-sdl/logger"
+)
+
+// execValidator holds the state of an executable document validation, see ValidateExecutable.
+type execValidator struct {
+	p     *Parser
+	frags map[ast.NameValue_]*ast.FragmentStmt
+	// fragment spreads and variable usages found in each fragment. The operation being validated uses the key "".
+	spreads map[ast.NameValue_][]*ast.FragmentSpread
+	usages  map[ast.NameValue_][]varUsage
+	scope   ast.NameValue_ // fragment being validated, "" for an operation
+	// object types implementing each interface, read from the document's statements when first required
+	implementors map[ast.NameValue_]ast.NameS
+	stmts        []db.TypeRow
+}
+
+// varUsage is a variable used as an input value.
+type varUsage struct {
+	ast.Name_               // variable name and location of its use
+	typ        *ast.GQLtype // type expected at the location
+	hasDefault bool         // location has a default value
+}
+
+// ValidateExecutable checks the operations and fragments from ParseExecutable against the types of the named document
+// (default document if not specified). Types are sourced from the cache, which will read the database if necessary.
+func (p *Parser) ValidateExecutable(d *ast.ExecDocument, doc ...string) []error {
+
+	document := defaultDoc
+	if len(doc) > 0 {
+		document = doc[0]
+	}
+	p.logr.Log(logger.Info, "validate executable", logger.Document(document), logger.Phase("validate"), logger.Any("operations", len(d.Operations)), logger.Any("fragments", len(d.Fragments)))
+
+	db.SetDefaultDoc(defaultDoc)
+	db.SetDocument(document)
+	LoadASTcache(p.cache)
+
+	v := &execValidator{
+		p:            p,
+		frags:        make(map[ast.NameValue_]*ast.FragmentStmt),
+		spreads:      make(map[ast.NameValue_][]*ast.FragmentSpread),
+		usages:       make(map[ast.NameValue_][]varUsage),
+		implementors: make(map[ast.NameValue_]ast.NameS),
+	}
+	//
+	// fragments are validated once, against their type condition
+	//
+	for _, f := range d.Fragments {
+		if first, ok := v.frags[f.Name]; ok {
+			p.addDiag(ast.CodeExecutable, f.Loc, `There can be only one fragment named "%s"`, f.Name).WithRelated("first defined here", first.Loc)
+			continue
+		}
+		v.frags[f.Name] = f
+	}
+	for _, f := range d.Fragments {
+		if v.frags[f.Name] != f {
+			continue
+		}
+		v.scope = f.Name
+		v.directives(f.Directives_, ast.FRAGMENT_DEFINITION_DL)
+		if t := v.typeCondition(f.TypeCond); t != nil {
+			v.selectionSet(t, f.SelectionSet)
+		}
+	}
+	v.checkFragmentCycles(d)
+	//
+	// operations
+	//
+	names := make(map[ast.NameValue_]bool)
+	for _, op := range d.Operations {
+		if !op.Name_.Exists() {
+			if len(d.Operations) > 1 {
+				p.addDiag(ast.CodeExecutable, op.Loc, `An anonymous operation must be the only operation in the document`)
+			}
+		} else if names[op.Name] {
+			p.addDiag(ast.CodeExecutable, op.Loc, `There can be only one operation named "%s"`, op.Name)
+		}
+		names[op.Name] = true
+	}
+	used := make(map[ast.NameValue_]bool) // fragments used by an operation
+	for _, op := range d.Operations {
+		v.scope = ""
+		v.spreads[""], v.usages[""] = nil, nil
+
+		v.operation(op)
+
+		// variables used by the operation include those in the fragments it spreads
+		usages := v.usages[""]
+		for f := range v.reachable() {
+			used[f] = true
+			usages = append(usages, v.usages[f]...)
+		}
+		v.checkVariableUsages(op, usages)
+	}
+	for _, f := range d.Fragments {
+		if !used[f.Name] {
+			p.addDiag(ast.CodeExecutable, f.Loc, `Fragment "%s" is never used`, f.Name)
+		}
+	}
+	return p.perror
+}
+
+// operation validates the operation against its root type as defined by the schema, or the default root type names when there is no schema.
+func (v *execValidator) operation(op *ast.OperationStmt) {
+	var (
+		loc  ast.DirectiveLoc
+		root ast.NameValue_
+	)
+	sc := v.p.Schema()
+
+	switch op.OpType {
+	case "query":
+		loc, root = ast.QUERY_DL, sc.Query.Name
+	case "mutation":
+		loc, root = ast.MUTATION_DL, sc.Mutation.Name
+	case "subscription":
+		loc, root = ast.SUBSCRIPTION_DL, sc.Subscription.Name
+		if len(op.SelectionSet) != 1 {
+			v.p.addDiag(ast.CodeExecutable, op.Loc, `Subscription %s must select only one top level field`, opName(op))
+		}
+	}
+	v.directives(op.Directives_, loc)
+	v.variableDefs(op)
+
+	if len(root) == 0 {
+		v.p.addDiag(ast.CodeExecutable, op.Loc, `Schema does not define a %s root type`, op.OpType)
+		return
+	}
+	if t, err := v.p.cache.FetchAST(root); err != nil {
+		v.p.addDiag(ast.CodeExecutable, op.Loc, `Root type "%s" of %s %s`, root, op.OpType, err)
+	} else if _, ok := t.(*ast.Object_); !ok {
+		v.p.addDiag(ast.CodeExecutable, op.Loc, `Root type "%s" of %s must be an Object type`, root, op.OpType)
+	} else {
+		v.selectionSet(t, op.SelectionSet)
+	}
+}
+
+func opName(op *ast.OperationStmt) string {
+	if op.Name_.Exists() {
+		return `"` + op.Name_.String() + `"`
+	}
+	return "(anonymous)"
+}
+
+// variableDefs checks each variable is unique, of an input type and its default value, if any, matches its type.
+func (v *execValidator) variableDefs(op *ast.OperationStmt) {
+	vars := make(map[ast.NameValue_]bool)
+	for _, vd := range op.VariableDefs {
+		if vars[vd.Name] {
+			v.p.addDiag(ast.CodeExecutable, vd.Loc, `There can be only one variable named "$%s"`, vd.Name)
+			continue
+		}
+		vars[vd.Name] = true
+		v.resolve(vd.Type)
+
+		switch vd.Type.IsType() {
+		case ast.ID, ast.INT, ast.FLOAT, ast.BOOLEAN, ast.STRING, ast.SCALAR, ast.ENUM, ast.INPUT:
+			if vd.DefaultVal != nil {
+				vd.DefaultVal.CheckInputValueType(vd.Type, vd.Name_, &v.p.perror)
+			}
+		case ast.ILLEGAL:
+			v.p.addDiag(ast.CodeExecutable, vd.Type.Loc, `Type "%s" of variable "$%s" does not exist`, vd.Type.Name, vd.Name)
+		default:
+			v.p.addDiag(ast.CodeExecutable, vd.Type.Loc, `Variable "$%s" cannot be non-input type "%s"`, vd.Name, vd.Type)
+		}
+		// there is no directive location for a variable definition, so its directives are not checked
+	}
+}
+
+// checkVariableUsages confirms each variable used by the operation is defined, of a type allowed at its location
+// and that each defined variable is used.
+func (v *execValidator) checkVariableUsages(op *ast.OperationStmt, usages []varUsage) {
+	used := make(map[ast.NameValue_]bool)
+	for _, u := range usages {
+		used[u.Name] = true
+		var vd *ast.VariableDef
+		for _, x := range op.VariableDefs {
+			if x.Name == u.Name {
+				vd = x
+				break
+			}
+		}
+		if vd == nil {
+			v.p.addDiag(ast.CodeExecutable, u.Loc, `Variable "$%s" is not defined by operation %s`, u.Name, opName(op))
+			continue
+		}
+		if !usageAllowed(vd, u) {
+			v.p.addDiag(ast.CodeExecutable, u.Loc, `Variable "$%s" of type "%s" used in position expecting type "%s"`, u.Name, vd.Type, u.typ)
+		}
+	}
+	for _, vd := range op.VariableDefs {
+		if !used[vd.Name] {
+			v.p.addDiag(ast.CodeExecutable, vd.Loc, `Variable "$%s" is never used in operation %s`, vd.Name, opName(op))
+		}
+	}
+}
+
+// usageAllowed: the variable type must equal the type at its location, though the variable may be more strict (non-null) at any depth.
+// A nullable variable is allowed in a non-null location when the variable or the location provides a default value.
+func usageAllowed(vd *ast.VariableDef, u varUsage) bool {
+	vt, lt := vd.Type, u.typ
+	if !vt.Name_.Equals(lt.Name_) || vt.Depth != lt.Depth {
+		return false
+	}
+	vc := vt.Constraint
+	if lt.Constraint>>lt.Depth&1 == 1 && vc>>vt.Depth&1 == 0 {
+		var nonNullDefault bool
+		if vd.DefaultVal != nil {
+			_, isNull := vd.DefaultVal.InputValueProvider.(ast.Null_)
+			nonNullDefault = !isNull
+		}
+		if !nonNullDefault && !u.hasDefault {
+			return false
+		}
+		vc |= 1 << vt.Depth
+	}
+	return lt.Constraint&^vc == 0
+}
+
+// selectionSet validates each selection against the parent type (Object_, Interface_ or Union_).
+func (v *execValidator) selectionSet(parent ast.GQLTypeProvider, ss ast.SelectionSet) {
+	for _, s := range ss {
+		switch x := s.(type) {
+
+		case *ast.Field:
+			v.field(parent, x)
+
+		case *ast.FragmentSpread:
+			v.directives(x.Directives_, ast.FRAGMENT_SPREAD_DL)
+			v.spreads[v.scope] = append(v.spreads[v.scope], x)
+			f, ok := v.frags[x.Name]
+			if !ok {
+				v.p.addDiag(ast.CodeExecutable, x.Loc, `Fragment "%s" is not defined`, x.Name)
+				continue
+			}
+			// an invalid type condition is reported with the fragment definition
+			if t, err := v.p.cache.FetchAST(f.TypeCond.Name); err == nil && !v.possibleSpread(parent, t) {
+				v.p.addDiag(ast.CodeExecutable, x.Loc, `Fragment "%s" cannot be spread here as objects of type "%s" can never be of type "%s"`, x.Name, parent.TypeName(), t.TypeName())
+			}
+
+		case *ast.InlineFragment:
+			v.directives(x.Directives_, ast.INLINE_FRAGMENT_DL)
+			t := parent
+			if x.TypeCond.Exists() {
+				if t = v.typeCondition(x.TypeCond); t == nil {
+					continue
+				}
+				if !v.possibleSpread(parent, t) {
+					v.p.addDiag(ast.CodeExecutable, x.Loc, `Fragment cannot be spread here as objects of type "%s" can never be of type "%s"`, parent.TypeName(), t.TypeName())
+				}
+			}
+			v.selectionSet(t, x.SelectionSet)
+		}
+	}
+}
+
+// field confirms the field is defined on the parent type, validates its arguments and directives and its selection set against the field's type.
+func (v *execValidator) field(parent ast.GQLTypeProvider, f *ast.Field) {
+
+	v.directives(f.Directives_, ast.FIELD_DL)
+
+	if f.Name == "__typename" {
+		if len(f.SelectionSet) > 0 {
+			v.p.addDiag(ast.CodeExecutable, f.Loc, `Field "%s" of type "String" must not have a selection set`, f.Name)
+		}
+		return
+	}
+	if strings.HasPrefix(f.Name.String(), "__") {
+		// introspection types are not held in the document
+		return
+	}
+	var fs ast.FieldSet
+	switch x := parent.(type) {
+	case *ast.Object_:
+		fs = x.FieldSet
+	case *ast.Interface_:
+		fs = x.FieldSet
+	}
+	var def *ast.Field_
+	for _, x := range fs {
+		if x.Name == f.Name {
+			def = x
+			break
+		}
+	}
+	if def == nil {
+		v.p.addDiag(ast.CodeExecutable, f.Loc, `Field "%s" is not defined on type "%s"`, f.Name, parent.TypeName())
+		return
+	}
+	v.arguments(f.Arguments, def.ArgumentDefs, fmt.Sprintf(`field "%s"`, f.Name), f.Name_)
+
+	v.resolve(def.Type)
+	switch def.Type.IsType() {
+	case ast.OBJECT, ast.INTERFACE, ast.UNION:
+		if len(f.SelectionSet) == 0 {
+			v.p.addDiag(ast.CodeExecutable, f.Loc, `Field "%s" of type "%s" must have a selection set of subfields`, f.Name, def.Type)
+		} else {
+			v.selectionSet(def.Type.AST, f.SelectionSet)
+		}
+	default:
+		if len(f.SelectionSet) > 0 {
+			v.p.addDiag(ast.CodeExecutable, f.Loc, `Field "%s" of type "%s" must not have a selection set`, f.Name, def.Type)
+		}
+	}
+}
+
+// arguments checks each argument is defined, unique and of the defined type, and that all required arguments are present.
+func (v *execValidator) arguments(args []*ast.ArgumentT, defs ast.InputValueDefs, owner string, at ast.Name_) {
+	seen := make(map[ast.NameValue_]bool)
+	for _, a := range args {
+		if seen[a.Name] {
+			v.p.addDiag(ast.CodeExecutable, a.Loc, `Duplicate argument "%s"`, a.Name)
+			continue
+		}
+		seen[a.Name] = true
+		var def *ast.InputValueDef
+		for _, x := range defs {
+			if x.Name == a.Name {
+				def = x
+				break
+			}
+		}
+		if def == nil {
+			v.p.addDiag(ast.CodeExecutable, a.Loc, `Argument "%s" is not defined on %s`, a.Name, owner)
+			continue
+		}
+		v.resolve(def.Type)
+		v.collectVariables(a.Value, def.Type, def.DefaultVal != nil)
+		a.Value.CheckInputValueType(def.Type, a.Name_, &v.p.perror)
+	}
+	for _, d := range defs {
+		if !seen[d.Name] && d.DefaultVal == nil && !d.Type.IsNullable() {
+			v.p.addDiag(ast.CodeExecutable, at.Loc, `Required argument "%s" of %s is missing`, d.Name, owner)
+		}
+	}
+}
+
+// collectVariables records the variables in the input value with the type expected at their location.
+func (v *execValidator) collectVariables(iv *ast.InputValue_, t *ast.GQLtype, hasDefault bool) {
+	if iv == nil {
+		return
+	}
+	switch x := iv.InputValueProvider.(type) {
+	case ast.Variable_:
+		v.usages[v.scope] = append(v.usages[v.scope], varUsage{Name_: x.Name_, typ: t, hasDefault: hasDefault})
+	case ast.List_:
+		if t.Depth == 0 {
+			return
+		}
+		item := &ast.GQLtype{Constraint: t.Constraint & (1<<t.Depth - 1), Depth: t.Depth - 1, Name_: t.Name_, AST: t.AST}
+		for _, e := range x {
+			v.collectVariables(e, item, false)
+		}
+	case ast.ObjectVals:
+		in, ok := t.AST.(*ast.Input_)
+		if !ok {
+			return
+		}
+		for _, a := range x {
+			for _, def := range in.InputValueDefs {
+				if def.Name == a.Name {
+					v.resolve(def.Type)
+					v.collectVariables(a.Value, def.Type, def.DefaultVal != nil)
+				}
+			}
+		}
+	}
+}
+
+// directives checks each directive is defined, permitted at the location, not repeated (unless repeatable) and its arguments.
+func (v *execValidator) directives(d ast.Directives_, loc ast.DirectiveLoc) {
+	for i, dir := range d.Directives {
+		x, _ := v.p.cache.FetchAST(dir.Name)
+		def, ok := x.(*ast.Directive_)
+		if !ok {
+			v.p.addDiag(ast.CodeExecutable, dir.Loc, `Directive "%s" is not defined`, dir.Name)
+			continue
+		}
+		var found bool
+		for _, l := range def.Location {
+			if l == loc {
+				found = true
+			}
+		}
+		if !found {
+			v.p.addDiag(ast.CodeExecutable, dir.Loc, `Directive "%s" is not registered for %s usage`, dir.Name, ast.DirectiveLocationMap[loc])
+		}
+		if !def.Repeatable {
+			for _, prev := range d.Directives[:i] {
+				if prev.Name_.Equals(dir.Name_) {
+					v.p.addDiag(ast.CodeExecutable, dir.Loc, `Duplicate Directive name "%s"`, dir.Name)
+					break
+				}
+			}
+		}
+		v.arguments(dir.Arguments, def.ArgumentDefs, fmt.Sprintf(`directive "%s"`, dir.Name), dir.Name_)
+	}
+}
+
+// typeCondition returns the type named in a fragment's type condition. It must be an Object, Interface or Union type.
+func (v *execValidator) typeCondition(n ast.Name_) ast.GQLTypeProvider {
+	t, err := v.p.cache.FetchAST(n.Name)
+	if err != nil && !errors.Is(err, ErrnotScalar) {
+		v.p.addDiag(ast.CodeExecutable, n.Loc, `Type "%s" %s`, n.Name, err)
+		return nil
+	}
+	switch t.(type) {
+	case *ast.Object_, *ast.Interface_, *ast.Union_:
+		return t
+	}
+	v.p.addDiag(ast.CodeExecutable, n.Loc, `Fragment cannot condition on non composite type "%s"`, n.Name)
+	return nil
+}
+
+// possibleSpread reports whether the parent and fragment types have an object type in common. When both are interfaces
+// the objects implementing each are compared, see interfaceTypes.
+func (v *execValidator) possibleSpread(parent, frag ast.GQLTypeProvider) bool {
+	if parent.TypeName() == frag.TypeName() {
+		return true
+	}
+	pt, pok := possibleTypes(parent)
+	ft, fok := possibleTypes(frag)
+	switch {
+	case pok && fok:
+		for _, n := range pt {
+			if ft.Contains(n.Name) {
+				return true
+			}
+		}
+		return false
+	case pok:
+		return v.implementedBy(frag.TypeName(), pt)
+	case fok:
+		return v.implementedBy(parent.TypeName(), ft)
+	}
+	pt, pok = v.interfaceTypes(parent.TypeName())
+	ft, fok = v.interfaceTypes(frag.TypeName())
+	if !pok || !fok {
+		// a database error has been reported
+		return true
+	}
+	for _, n := range pt {
+		if ft.Contains(n.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// interfaceTypes returns the object types of the document that implement the interface. Only a stored statement that
+// mentions the interface is fetched. It is false when the document's statements cannot be read.
+func (v *execValidator) interfaceTypes(itf ast.NameValue_) (ast.NameS, bool) {
+	if objs, ok := v.implementors[itf]; ok {
+		return objs, true
+	}
+	if v.stmts == nil {
+		rows, err := db.DocumentStatements()
+		if err != nil {
+			v.p.logr.Log(logger.Error, err.Error(), logger.Document(db.GetDocument()), logger.Phase("validate"))
+			v.p.addDiag(ast.CodeDatabase, nil, "%w", err)
+			return nil, false
+		}
+		v.stmts = rows
+	}
+	var objs ast.NameS
+	for _, stored := range v.stmts {
+		if !mentions(stored.Stmt, itf.String()) {
+			continue
+		}
+		if t, err := v.p.cache.FetchAST(ast.NameValue_(stored.PKey)); err == nil {
+			if o, ok := t.(*ast.Object_); ok && o.Implements.Contains(itf) {
+				objs = append(objs, o.Name_)
+			}
+		}
+	}
+	v.implementors[itf] = objs
+	return objs, true
+}
+
+// possibleTypes returns the object types of an Object_ or Union_. It is false for an Interface_.
+func possibleTypes(t ast.GQLTypeProvider) (ast.NameS, bool) {
+	switch x := t.(type) {
+	case *ast.Object_:
+		return ast.NameS{x.Name_}, true
+	case *ast.Union_:
+		return x.NameS, true
+	}
+	return nil, false
+}
+
+// implementedBy reports whether any of the objects implements the interface.
+func (v *execValidator) implementedBy(itf ast.NameValue_, objs ast.NameS) bool {
+	for _, n := range objs {
+		if t, err := v.p.cache.FetchAST(n.Name); err == nil {
+			if o, ok := t.(*ast.Object_); ok && o.Implements.Contains(itf) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkFragmentCycles reports a fragment spread that leads back to a fragment on the current path of spreads.
+func (v *execValidator) checkFragmentCycles(d *ast.ExecDocument) {
+	var (
+		done   = make(map[ast.NameValue_]bool)
+		onPath = make(map[ast.NameValue_]bool)
+		visit  func(name ast.NameValue_)
+	)
+	visit = func(name ast.NameValue_) {
+		onPath[name] = true
+		for _, s := range v.spreads[name] {
+			if onPath[s.Name] {
+				v.p.addDiag(ast.CodeExecutable, s.Loc, `Cannot spread fragment "%s" within itself`, s.Name)
+				continue
+			}
+			if !done[s.Name] && v.frags[s.Name] != nil {
+				visit(s.Name)
+			}
+		}
+		onPath[name] = false
+		done[name] = true
+	}
+	for _, f := range d.Fragments {
+		if !done[f.Name] {
+			visit(f.Name)
+		}
+	}
+}
+
+// reachable returns the fragments spread by the current operation, directly or via other fragments.
+func (v *execValidator) reachable() map[ast.NameValue_]bool {
+	found := make(map[ast.NameValue_]bool)
+	var walk func(name ast.NameValue_)
+	walk = func(name ast.NameValue_) {
+		for _, s := range v.spreads[name] {
+			if !found[s.Name] && v.frags[s.Name] != nil {
+				found[s.Name] = true
+				walk(s.Name)
+			}
+		}
+	}
+	walk("")
+	return found
+}
+
+// resolve assigns the AST of a named type that is not a built-in scalar. The type is also made
+// available to the ast package checks e.g. CheckInputValueType, which use ast.TyCache.
+func (v *execValidator) resolve(t *ast.GQLtype) {
+	if t == nil || t.AST != nil || t.IsScalar() {
+		return
+	}
+	if x, err := v.p.cache.FetchAST(t.Name); err == nil {
+		t.Lock()
+		t.AST = x
+		t.Unlock()
+		ast.TyCache[t.Name.String()] = x
+	}
+}
@@ -0,0 +1,219 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/rosshpayne/graph-sdl/lexer"
+)
+
+// the schema is created in its own document as it defines the root types.
+const val32Doc = "Val32"
+
+func TestValidateExecSetup(t *testing.T) {
+
+	input := `
+schema {
+  query: Val32Query
+  mutation: Val32Mutation
+}
+
+enum Episode { NEWHOPE EMPIRE JEDI }
+
+interface Character {
+  id: ID!
+  name: String
+  friends: [Character]
+}
+
+interface Named {
+  name: String
+}
+
+interface Vehicle {
+  id: ID!
+}
+
+type Human implements Character & Named {
+  id: ID!
+  name: String
+  friends: [Character]
+  height: Float
+}
+
+type Droid implements Character {
+  id: ID!
+  name: String
+  friends: [Character]
+  primaryFunction: String
+}
+
+type Starship implements Vehicle {
+  id: ID!
+}
+
+union SearchResult = | Human | Droid
+
+input Filter {
+  ids: [ID!]
+  first: Int
+}
+
+type Val32Query {
+  hero(episode: Episode, filter: Filter): Character
+  search(text: String!): [SearchResult]
+  human(id: ID!): Human
+}
+
+type Val32Mutation {
+  like(id: ID!): Int
+}
+`
+	l := lexer.New(input)
+	p := New(l)
+	_, errs := p.ParseDocument(val32Doc)
+	for _, v := range errs {
+		t.Errorf(`Unexpected error: %s`, v.Error())
+	}
+}
+
+func TestValidateExec(t *testing.T) {
+
+	input := `
+query HeroNameAndFriends($episode: Episode = JEDI, $withFriends: Boolean! = true, $ids: [ID!]) {
+  hero(episode: $episode, filter: {ids: $ids, first: 10}) {
+    name
+    __typename
+    friends @include(if: $withFriends) {
+      name
+      ... on Droid { primaryFunction }
+      ...humanFields
+    }
+  }
+}
+
+mutation Like($id: ID!) { like(id: $id) }
+
+fragment humanFields on Human { height }
+`
+	l := lexer.New(input)
+	p := New(l)
+	d, errs := p.ParseExecutable()
+	if len(errs) == 0 {
+		errs = p.ValidateExecutable(d, val32Doc)
+	}
+	for _, v := range errs {
+		t.Errorf(`Unexpected error: %s`, v.Error())
+	}
+}
+
+func TestValidateExecErrors(t *testing.T) {
+
+	input := `
+query Q($e: Episode, $unused: Int, $id: ID) {
+  hero(episode: $e, bad: 1) { nope id { x } }
+  search { ... on Human { height } ... on Episode { x } }
+  human(id: $id) { ...droidFields ...missing }
+  hero(episode: MARS) @skip(if: true) @skip(if: false) { id }
+}
+
+query Q { like }
+
+fragment droidFields on Droid { primaryFunction ...cyc }
+
+fragment cyc on Droid { ...droidFields }
+
+fragment unused on Human { height }
+`
+	var expectedErr [15]string
+	expectedErr[0] = `Cannot spread fragment "droidFields" within itself at line: 13 column: 28`
+	expectedErr[1] = `There can be only one operation named "Q" at line: 9 column: 7`
+	expectedErr[2] = `Argument "bad" is not defined on field "hero" at line: 3 column: 21`
+	expectedErr[3] = `Field "nope" is not defined on type "Character" at line: 3 column: 31`
+	expectedErr[4] = `Field "id" of type "ID!" must not have a selection set at line: 3 column: 36`
+	expectedErr[5] = `Required argument "text" of field "search" is missing at line: 4 column: 3`
+	expectedErr[6] = `Fragment cannot condition on non composite type "Episode" at line: 4 column: 43`
+	expectedErr[7] = `Fragment "droidFields" cannot be spread here as objects of type "Human" can never be of type "Droid" at line: 5 column: 23`
+	expectedErr[8] = `Fragment "missing" is not defined at line: 5 column: 38`
+	expectedErr[9] = `Duplicate Directive name "@skip" at line: 6 column: 40`
+	expectedErr[10] = ` "MARS" is not a member of Enum type Episode at line: 6 column: 17`
+	expectedErr[11] = `Variable "$id" of type "ID" used in position expecting type "ID!" at line: 5 column: 14`
+	expectedErr[12] = `Variable "$unused" is never used in operation "Q" at line: 2 column: 23`
+	expectedErr[13] = `Field "like" is not defined on type "Val32Query" at line: 9 column: 11`
+	expectedErr[14] = `Fragment "unused" is never used at line: 15 column: 10`
+
+	l := lexer.New(input)
+	p := New(l)
+	d, errs := p.ParseExecutable()
+	if len(errs) == 0 {
+		errs = p.ValidateExecutable(d, val32Doc)
+	}
+	for _, ex := range expectedErr {
+		found := false
+		for _, err := range errs {
+			if trimWS(err.Error()) == trimWS(ex) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf(`Expected Error = [%q]`, ex)
+		}
+	}
+	for _, got := range errs {
+		found := false
+		for _, exp := range expectedErr {
+			if trimWS(got.Error()) == trimWS(exp) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf(`Unexpected Error = [%q]`, got.Error())
+		}
+	}
+}
+
+func TestValidateExecInterfaceSpread(t *testing.T) {
+
+	input := `
+query Q {
+  hero {
+    ... on Named { name }
+    ... on Vehicle { id }
+    ...vehicleFields
+  }
+}
+
+fragment vehicleFields on Vehicle { id }
+`
+	var expectedErr [2]string
+	expectedErr[0] = `Fragment cannot be spread here as objects of type "Character" can never be of type "Vehicle" at line: 5 column: 5`
+	expectedErr[1] = `Fragment "vehicleFields" cannot be spread here as objects of type "Character" can never be of type "Vehicle" at line: 6 column: 8`
+
+	l := lexer.New(input)
+	p := New(l)
+	d, errs := p.ParseExecutable()
+	if len(errs) == 0 {
+		errs = p.ValidateExecutable(d, val32Doc)
+	}
+	for _, ex := range expectedErr {
+		found := false
+		for _, err := range errs {
+			if trimWS(err.Error()) == trimWS(ex) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf(`Expected Error = [%q]`, ex)
+		}
+	}
+	for _, got := range errs {
+		found := false
+		for _, exp := range expectedErr {
+			if trimWS(got.Error()) == trimWS(exp) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf(`Unexpected Error = [%q]`, got.Error())
+		}
+	}
+}
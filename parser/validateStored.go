@@ -0,0 +1,202 @@
+package parser
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/rosshpayne/graph-sdl/ast"
+	"github.com/rosshpayne/graph-sdl/internal/db"
+	"github.com/rosshpayne/graph-sdl/lexer"
+	"github.com/rosshpayne/graph-sdl/logger"
+)
+
+// validateDependents rechecks the stored types of the document that depend on a stored type the document changes,
+// e.g. an object implementing a changed interface, a default value of a changed enum or the use of a changed directive.
+// A failure is reported against each changed type the dependent references. It rejects the change, or when
+// dependents do not block (see SetBlockOnDependents) is reported as a warning.
+func (p *Parser) validateDependents(api *ast.Document, document string) {
+	//
+	// stored types changed by the document
+	//
+	changed := make(map[ast.NameValue_]bool)
+	for name, v := range api.StatementsMap {
+		if len(api.ErrorMap[name]) > 0 {
+			continue
+		}
+		stored, err := p.storedRow(name)
+		if err != nil {
+			// reported when the statement is applied
+			continue
+		}
+		if stored != nil && !sameStatement(stored.Stmt, v.String()) {
+			changed[name] = true
+		}
+	}
+	if len(changed) == 0 {
+		return
+	}
+	rows, err := db.DocumentStatements()
+	if err != nil {
+		p.logr.Log(logger.Error, err.Error(), logger.Document(document), logger.Phase("validate"))
+		p.addDiag(ast.CodeDatabase, nil, "%w", err)
+		return
+	}
+	//
+	// stored types that reference a changed type. Each is parsed afresh, so it is resolved against the changed types.
+	// Only a statement that mentions a changed type is parsed.
+	//
+	type dependent struct {
+		stmt ast.GQLTypeProvider
+		refs []ast.NameValue_ // changed types referenced
+	}
+	var dependents []dependent
+	for _, stored := range rows {
+		if _, ok := api.StatementsMap[ast.NameValue_(stored.PKey)]; ok {
+			continue
+		}
+		var mentioned bool
+		for name := range changed {
+			if mentions(stored.Stmt, name.String()) {
+				mentioned = true
+				break
+			}
+		}
+		if !mentioned {
+			continue
+		}
+		p2 := New(lexer.New(stored.Stmt))
+		p2.logr = p.logr
+		stmt := p2.ParseStatement()
+		if stmt == nil || len(p2.perror) > 0 {
+			continue
+		}
+		refs := make(ast.UnresolvedMap)
+		stmt.SolicitAbstractTypes(refs)
+		d := dependent{stmt: stmt}
+		for ref := range refs {
+			if changed[ref.Name] {
+				d.refs = append(d.refs, ref.Name)
+			}
+		}
+		if len(d.refs) == 0 {
+			continue
+		}
+		p2.resolveDependents(stmt, p.cache)
+		dependents = append(dependents, d)
+	}
+	if len(dependents) == 0 {
+		return
+	}
+	LoadASTcache(p.cache)
+	for k, v := range p.extended {
+		ast.TyCache[k.String()] = v
+	}
+	//
+	// recheck each dependent
+	//
+	for _, d := range dependents {
+		var errs []error
+		switch x := d.stmt.(type) {
+		case *ast.Object_:
+			x.CheckImplements(&errs)
+		case *ast.Interface_:
+			x.CheckImplements(&errs)
+		}
+		d.stmt.CheckInputValueType(&errs)
+		d.stmt.CheckDirectiveLocation(&errs)
+		p.logr.Log(logger.Debug, "revalidated dependent", logger.Document(document), logger.Type(d.stmt.TypeName().String()), logger.Phase("validate"), logger.Any("errors", len(errs)))
+		for _, e := range errs {
+			msg := e.Error()
+			var diag *ast.Diagnostic
+			if errors.As(e, &diag) {
+				// the location is in the stored statement, not the document
+				msg = diag.Message
+			}
+			for _, name := range d.refs {
+				dep := ast.Diagf(ast.CodeDependent, nil, `Change to "%s" invalidates stored %s "%s": %s`, name, d.stmt.Type(), d.stmt.TypeName(), msg)
+				dep.TypeName = name
+				if p.blockDependents {
+					api.ErrorMap[name] = append(api.ErrorMap[name], dep)
+				} else {
+					dep.Severity = ast.SevWarning
+					p.perror = append(p.perror, dep)
+				}
+			}
+		}
+	}
+}
+
+// mentions reports whether the name occurs in the statement as a whole name, e.g. Dep is not mentioned by Depth.
+func mentions(stmt string, name string) bool {
+	isNameChar := func(c byte) bool {
+		return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+	}
+	for i := strings.Index(stmt, name); i >= 0; {
+		end := i + len(name)
+		if (i == 0 || !isNameChar(stmt[i-1])) && (end == len(stmt) || !isNameChar(stmt[end])) {
+			return true
+		}
+		j := strings.Index(stmt[i+1:], name)
+		if j < 0 {
+			break
+		}
+		i += j + 1
+	}
+	return false
+}
+
+// validateRemovals rejects a change that removes a member of a stored type before the deprecation policy permits,
+// see SetDeprecationPolicy. The removal of a field covers the removal of its arguments.
+func (p *Parser) validateRemovals(api *ast.Document) {
+	if p.deprecateVersions <= 0 && p.deprecateDays <= 0 {
+		return
+	}
+	for _, v := range api.Statements {
+		name := v.TypeName()
+		if api.StatementsMap[name] != v || len(api.ErrorMap[name]) > 0 {
+			continue
+		}
+		stored, err := p.storedRow(name)
+		if err != nil || stored == nil || sameStatement(stored.Stmt, v.String()) {
+			// a database error is reported when the statement is applied
+			continue
+		}
+		p2 := New(lexer.New(stored.Stmt))
+		p2.logr = p.logr
+		prev := p2.ParseStatement()
+		if prev == nil || len(p2.perror) > 0 {
+			continue
+		}
+		deprecated := stored.Deprecations(prev)
+		current := make(map[string]bool)
+		for _, m := range ast.Members(v) {
+			current[m.Path] = true
+		}
+		removed := make(map[string]bool)
+		revision := stored.Revision() + 1
+		for _, m := range ast.Members(prev) {
+			if current[m.Path] {
+				continue
+			}
+			removed[m.Path] = true
+			if i := strings.IndexByte(m.Path, '('); i > 0 && removed[m.Path[:i]] {
+				continue
+			}
+			var diag *ast.Diagnostic
+			dp, ok := deprecated[m.Path]
+			switch {
+			case !ok:
+				diag = ast.Diagf(ast.CodeDeprecated, nil, `%s "%s" of %s "%s" cannot be removed as it is not deprecated`, m.Kind, m.Path, v.Type(), name)
+			case p.deprecateVersions > 0 && revision-dp.R < p.deprecateVersions:
+				diag = ast.Diagf(ast.CodeDeprecated, nil, `%s "%s" of %s "%s" cannot be removed until it has been deprecated for %d versions, it was deprecated in revision %d`, m.Kind, m.Path, v.Type(), name, p.deprecateVersions, dp.R)
+			case p.deprecateDays > 0 && time.Since(dp.Since()) < time.Duration(p.deprecateDays)*24*time.Hour:
+				diag = ast.Diagf(ast.CodeDeprecated, nil, `%s "%s" of %s "%s" cannot be removed until it has been deprecated for %d days, it was deprecated on %s`, m.Kind, m.Path, v.Type(), name, p.deprecateDays, dp.Since().Format("2006-01-02"))
+			}
+			if diag != nil {
+				diag.TypeName = name
+				api.ErrorMap[name] = append(api.ErrorMap[name], diag)
+			}
+		}
+	}
+}